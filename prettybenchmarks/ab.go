@@ -0,0 +1,241 @@
+package prettybenchmarks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultABArgs are passed to the test binaries by 'pb ab' before the user's flags, which may override them
+var defaultABArgs = []string{"-test.run=^$", "-test.bench=.", "-test.benchmem"}
+
+// testFlags are the flags of go test which the test binary knows prefixed with "test."
+var testFlags = []string{
+	"bench", "benchmem", "benchtime", "blockprofile", "blockprofilerate", "count", "coverprofile", "cpu",
+	"cpuprofile", "failfast", "memprofile", "memprofilerate", "mutexprofile", "mutexprofilefraction",
+	"outputdir", "parallel", "run", "short", "shuffle", "skip", "timeout", "trace", "v",
+}
+
+// abCommand implements 'pb ab <a> [b] [-- go test flags]': it builds the test binaries of two trees,
+// each a directory or else a git ref checked out into a temporary worktree (b defaults to the working tree),
+// and runs them alternately with -count=1 for --rounds rounds, so that a machine getting slower or faster
+// over time affects both alike. The samples of all rounds are compared like in compare mode
+func abCommand(args []string) error {
+	trees, flags := splitArgs(args)
+
+	if len(trees) == 1 {
+		trees = append(trees, ".")
+	}
+
+	if len(trees) != 2 || strings.HasPrefix(trees[0], "-") {
+		return errors.New("usage: pb ab <a> [b] [-- go test flags]")
+	}
+
+	if *roundsFlag < 1 {
+		return fmt.Errorf("invalid number of rounds %d", *roundsFlag)
+	}
+
+	var cleanups []func()
+
+	// worktrees are removed before the directory holding the test binaries
+	defer onInterrupt(func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	})()
+
+	tmp, err := ioutil.TempDir("", "pb-ab")
+
+	if err != nil {
+		return err
+	}
+
+	cleanups = append(cleanups, func() { os.RemoveAll(tmp) })
+
+	dirs := make([]string, len(trees))
+	binaries := make([]string, len(trees))
+
+	for i, tree := range trees {
+		dir, remove, err := resolveTree(tree)
+
+		if err != nil {
+			return err
+		}
+
+		cleanups = append(cleanups, remove)
+
+		fmt.Fprintf(os.Stderr, "building %s\n", tree)
+
+		dirs[i] = dir
+		binaries[i] = filepath.Join(tmp, fmt.Sprintf("%d.test", i))
+
+		if _, err := goTest(dir, []string{"-c", "-o", binaries[i]}); err != nil {
+			return err
+		}
+	}
+
+	runArgs := testBinaryArgs(flags)
+	samples := make([][][]byte, len(trees))
+
+	for round := 0; round < *roundsFlag; round++ {
+		for _, i := range abOrder(round) {
+			if progressEnabled {
+				fmt.Fprintf(os.Stderr, "\r\033[Kround %d/%d, running %s", round+1, *roundsFlag, trees[i])
+			}
+
+			l, err := runTestBinary(dirs[i], binaries[i], runArgs)
+
+			if err != nil {
+				return err
+			}
+
+			// config lines and the summary of go test are only kept of the first run of the first tree,
+			// both trees print the same ones in every round
+			if !keepsSummary(round, i) {
+				l = resultLines(l)
+			}
+
+			samples[i] = append(samples[i], l...)
+		}
+	}
+
+	if progressEnabled {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+
+	inputs := make([]*input, 0, len(trees))
+
+	for i, tree := range trees {
+		if tree == "." {
+			tree = workingTreeLabel
+		}
+
+		inputs = append(inputs, &input{tree, samples[i]})
+	}
+
+	cmp, err := newComparison(inputs, *baselineFlag)
+
+	if err != nil {
+		return err
+	}
+
+	diagnostics := inputDiagnostics(inputs)
+	printDiagnostics(os.Stderr, diagnostics)
+
+	bench = cmp.merged
+	printComparison(cmp)
+	fmt.Println(footer())
+
+	return strictError(diagnostics)
+}
+
+// resolveTree returns the package directory to build for tree, which is either a directory or a git ref
+// checked out into a temporary worktree, along with a function removing that worktree again
+func resolveTree(tree string) (string, func(), error) {
+	if fi, err := os.Stat(tree); err == nil && fi.IsDir() {
+		return tree, func() {}, nil
+	}
+
+	// the package directory relative to the repository root, to build the same package of the worktree
+	prefix, err := gitOutput(".", "rev-parse", "--show-prefix")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	worktree, remove, err := addWorktree(".", tree)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Join(worktree, filepath.FromSlash(prefix)), remove, nil
+}
+
+// abOrder returns the order the two test binaries run in the given round, alternating which one goes first
+func abOrder(round int) []int {
+	if round%2 == 1 {
+		return []int{1, 0}
+	}
+
+	return []int{0, 1}
+}
+
+// testBinaryArgs translates go test flags into the ones of a test binary and makes it run every benchmark once
+func testBinaryArgs(flags []string) []string {
+	args := append([]string{}, defaultABArgs...)
+
+	for _, f := range flags {
+		args = append(args, testBinaryFlag(f))
+	}
+
+	return append(args, "-test.count=1")
+}
+
+// testBinaryFlag prefixes a go test flag like -benchtime=2s with "test.", other arguments are returned unchanged
+func testBinaryFlag(arg string) string {
+	if !strings.HasPrefix(arg, "-") {
+		return arg
+	}
+
+	name := strings.TrimLeft(arg, "-")
+
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+
+	if !StringsContains(testFlags, name) {
+		return arg
+	}
+
+	return "-test." + strings.TrimLeft(arg, "-")
+}
+
+// runTestBinary runs a test binary built by go test -c in dir and returns the lines it printed
+func runTestBinary(dir, binary string, args []string) ([][]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		l, _ := readLines(&stdout)
+		errLines, _ := readLines(&stderr)
+
+		for _, line := range append(l, errLines...) {
+			fmt.Fprintln(os.Stderr, string(line))
+		}
+
+		return nil, fmt.Errorf("%s in %s: %s", filepath.Base(binary), dir, err)
+	}
+
+	return readLines(&stdout)
+}
+
+// keepsSummary reports whether the run of the tree with the given index in round keeps its config and status lines
+func keepsSummary(round, tree int) bool {
+	return round == 0 && tree == 0
+}
+
+// resultLines returns the lines making up benchmark results, i.e. the ones the parser attributes to a benchmark,
+// as with -v or benchmarks logging their names and measurements are printed on different lines
+func resultLines(l [][]byte) [][]byte {
+	var kept [][]byte
+
+	p := &lineParser{}
+
+	for _, line := range l {
+		if _, err := p.parse(line); err == nil {
+			kept = append(kept, line)
+		}
+	}
+
+	return kept
+}
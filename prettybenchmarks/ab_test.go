@@ -0,0 +1,85 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_testBinaryArgs(t *testing.T) {
+	for _, tt := range []struct {
+		flags    []string
+		expected []string
+	}{
+		{nil, []string{"-test.run=^$", "-test.bench=.", "-test.benchmem", "-test.count=1"}},
+		{
+			[]string{"-bench=Foo", "--benchtime", "2s", "-test.cpu=1,4", "-custom=x"},
+			[]string{"-test.run=^$", "-test.bench=.", "-test.benchmem", "-test.bench=Foo", "-test.benchtime", "2s", "-test.cpu=1,4", "-custom=x", "-test.count=1"},
+		},
+	} {
+		if actual := testBinaryArgs(tt.flags); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Translating %v: expected %v, actual %v", tt.flags, tt.expected, actual)
+		}
+	}
+}
+
+func Test_abOrder(t *testing.T) {
+	var first []int
+
+	for round := 0; round < 4; round++ {
+		first = append(first, abOrder(round)[0])
+	}
+
+	if expected := []int{0, 1, 0, 1}; !reflect.DeepEqual(first, expected) {
+		t.Errorf("Alternating order: expected %v, actual %v", expected, first)
+	}
+}
+
+func Test_resultLines(t *testing.T) {
+	l := [][]byte{
+		[]byte("goos: linux\n"),
+		[]byte("Benchmark_Foo-8 	 100	 1000 ns/op\n"),
+		[]byte("PASS\n"),
+	}
+
+	if actual := resultLines(l); len(actual) != 1 || string(actual[0]) != string(l[1]) {
+		t.Errorf("Keeping result lines: expected %q, actual %q", l[1:2], actual)
+	}
+}
+
+func Test_resultLinesInterleaved(t *testing.T) {
+	defer func() { benchmarkLogs = nil }()
+
+	l := [][]byte{
+		[]byte("goos: linux\n"),
+		[]byte("BenchmarkFoo\n"),
+		[]byte("    foo_test.go:10: warming up\n"),
+		[]byte("BenchmarkFoo-8   \t 100\t 1000 ns/op\n"),
+		[]byte("PASS\n"),
+	}
+
+	kept := resultLines(l)
+
+	if expected := l[1:4]; !reflect.DeepEqual(kept, expected) {
+		t.Errorf("Keeping interleaved result lines: expected %q, actual %q", expected, kept)
+	}
+
+	if rs := (*newResults(kept))["Foo"]; len(rs) != 1 || rs[0].Speed != 1000 {
+		t.Errorf("Parsing kept lines: expected one result of 1000 ns/op, actual %v", rs)
+	}
+}
+
+func Test_keepsSummary(t *testing.T) {
+	kept := 0
+
+	for round := 0; round < 3; round++ {
+		for _, i := range abOrder(round) {
+			if keepsSummary(round, i) {
+				kept++
+			}
+		}
+	}
+
+	if kept != 1 {
+		t.Errorf("Keeping the summary of 3 rounds of 2 trees: expected 1 run, actual %d", kept)
+	}
+}
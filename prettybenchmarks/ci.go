@@ -0,0 +1,125 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// bootstrapResamples is the number of resamples the distribution of a median is estimated from
+const bootstrapResamples = 1000
+
+// ciLevel is the confidence level in percent of the intervals computed for aggregated metrics, 0 if disabled
+var ciLevel float64
+
+// ciSeed seeds the random resampling, the same seed always yields the same intervals
+var ciSeed int64
+
+// interval is a bootstrap confidence interval of the median of a metric
+type interval struct {
+	Level float64
+	Low   float64
+	High  float64
+}
+
+// setCI parses the --ci and --seed flags, a seed of 0 picks a random one
+func setCI(level float64, seed int64) error {
+	if level < 0 || level >= 100 {
+		return fmt.Errorf("invalid confidence level %v, use 0 < level < 100 or 0 to disable intervals", level)
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	ciLevel, ciSeed = level, seed
+
+	return nil
+}
+
+// bootstrapCI estimates the confidence interval of the median of values at the given level by resampling them
+func bootstrapCI(values []float64, level float64, seed int64) *interval {
+	rng := rand.New(rand.NewSource(seed))
+	medians := make([]float64, bootstrapResamples)
+	resample := make([]float64, len(values))
+
+	for i := range medians {
+		for j := range resample {
+			resample[j] = values[rng.Intn(len(values))]
+		}
+
+		medians[i] = median(resample)
+	}
+
+	sort.Float64s(medians)
+	tail := (1 - level/100) / 2
+
+	return &interval{level, quantile(medians, tail), quantile(medians, 1-tail)}
+}
+
+// intervals computes the confidence interval of the median of every metric of samples, nil if disabled
+func intervals(samples []*result) map[string]*interval {
+	if ciLevel == 0 || len(samples) < 2 {
+		return nil
+	}
+
+	units := []string{"ns/op", "B/op", "allocs/op"}
+
+	for _, r := range samples {
+		for unit := range r.Metrics {
+			if !StringsContains(units, unit) {
+				units = append(units, unit)
+			}
+		}
+	}
+
+	ivs := make(map[string]*interval, len(units))
+
+	for _, unit := range units {
+		var values []float64
+
+		for _, r := range samples {
+			if v, ok := metricValue(r, unit); ok {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) > 0 {
+			ivs[unit] = bootstrapCI(values, ciLevel, ciSeed)
+		}
+	}
+
+	return ivs
+}
+
+// metricValue returns r's value of the metric with the given unit and whether r has it
+func metricValue(r *result, unit string) (float64, bool) {
+	switch unit {
+	case "ns/op":
+		return r.Speed, r.Speed >= 0
+	case "B/op":
+		return bytesPerOp(r), r.Bps >= 0
+	case "allocs/op":
+		return allocsPerOp(r), r.Aps >= 0
+	}
+
+	v, ok := r.Metrics[unit]
+
+	return v, ok
+}
+
+// formatInterval renders the half width of r's confidence interval of unit relative to r's value in percent
+func formatInterval(r *result, unit string) string {
+	iv := r.Intervals[unit]
+	v, ok := metricValue(r, unit)
+
+	if iv == nil || !ok || v <= 0 {
+		return ""
+	}
+
+	width := math.Max(v-iv.Low, iv.High-v)
+
+	return " ± " + RenderFloat(fmtFloatUnit, width/v*100) + "%"
+}
@@ -0,0 +1,140 @@
+package prettybenchmarks
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_setCI(t *testing.T) {
+	defer setCI(0, 1)
+
+	for _, tt := range []struct {
+		level float64
+		err   bool
+	}{
+		{0, false},
+		{95, false},
+		{100, true},
+		{-5, true},
+	} {
+		if err := setCI(tt.level, 1); (err != nil) != tt.err {
+			t.Errorf("Setting confidence level %v: expected error %v, got %v", tt.level, tt.err, err)
+		}
+	}
+
+	if setCI(95, 0); ciSeed == 0 {
+		t.Errorf("Setting seed 0: expected a random seed")
+	}
+}
+
+func Test_bootstrapCI(t *testing.T) {
+	values := []float64{100, 102, 98, 101, 99, 103, 97, 100, 101, 99}
+
+	iv := bootstrapCI(values, 95, 42)
+
+	if !reflect.DeepEqual(iv, bootstrapCI(values, 95, 42)) {
+		t.Errorf("Bootstrapping with the same seed: expected the same interval")
+	}
+
+	if iv.Level != 95 || iv.Low > 100 || iv.High < 100 || iv.Low < 97 || iv.High > 103 {
+		t.Errorf("Bootstrapping %v: expected interval around 100 within the samples, actual %#v", values, iv)
+	}
+
+	if narrow := bootstrapCI(values, 50, 42); narrow.High-narrow.Low > iv.High-iv.Low {
+		t.Errorf("Bootstrapping at a lower level: expected narrower interval than %#v, actual %#v", iv, narrow)
+	}
+}
+
+func Test_formatInterval(t *testing.T) {
+	r := &result{Speed: 200, Bps: 0, Intervals: map[string]*interval{
+		"ns/op": {95, 190, 204},
+		"B/op":  {95, 0, 0},
+	}}
+
+	for unit, expected := range map[string]string{
+		"ns/op":     " ± 5.00%",
+		"B/op":      "",
+		"allocs/op": "",
+	} {
+		if actual := formatInterval(r, unit); actual != expected {
+			t.Errorf("Formatting interval of %s: expected %q, actual %q", unit, expected, actual)
+		}
+	}
+}
+
+func Test_aggregateIntervals(t *testing.T) {
+	defer setCI(0, 1)
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+	setCI(95, 1)
+
+	bm := aggregateBenchmark(newBenchmark([][]byte{
+		[]byte("Benchmark_Foo-8 	 100	 1000 ns/op	 100 B/op	 2 allocs/op	 5.00 widgets/op\n"),
+		[]byte("Benchmark_Foo-8 	 100	 1010 ns/op	 100 B/op	 2 allocs/op	 5.00 widgets/op\n"),
+		[]byte("Benchmark_Foo-8 	 100	 990 ns/op	 100 B/op	 2 allocs/op	 5.00 widgets/op\n"),
+	}))
+
+	foo := (*bm.results)["Foo"][0]
+
+	if ivs := foo.Intervals; ivs["ns/op"] == nil || ivs["B/op"] == nil || ivs["widgets/op"] == nil {
+		t.Fatalf("Aggregating with intervals: expected intervals of every metric, actual %#v", ivs)
+	}
+
+	cols, err := selectColumns(bm, "time,allocs")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := formatCell(cols[0], bm, foo, true); !strings.HasPrefix(actual, "1.000 ± ") {
+		t.Errorf("Rendering time with interval: expected \"1.000 ± ...\", actual %q", actual)
+	}
+
+	if actual := formatCell(cols[1], bm, foo, true); actual != "2 ± 0.00%" {
+		t.Errorf("Rendering allocs with interval: expected %q, actual %q", "2 ± 0.00%", actual)
+	}
+
+	out, err := json.Marshal(foo)
+
+	if err != nil || !strings.Contains(string(out), `"Intervals":{`) {
+		t.Errorf("Encoding result with intervals: expected Intervals in %s (%v)", out, err)
+	}
+}
+
+func Test_aggregateIntervalsOutliers(t *testing.T) {
+	defer setCI(0, 1)
+	defer setOutlierMode("")
+
+	setCI(95, 1)
+
+	samples := []*result{
+		{Name: "Foo", Speed: 100, Bps: -1, Aps: -1},
+		{Name: "Foo", Speed: 101, Bps: -1, Aps: -1},
+		{Name: "Foo", Speed: 102, Bps: -1, Aps: -1},
+		{Name: "Foo", Speed: 110, Bps: -1, Aps: -1},
+		{Name: "Foo", Speed: 1000, Bps: -1, Aps: -1},
+	}
+
+	for _, tt := range []struct {
+		mode     string
+		expected float64
+	}{
+		{"none", 102},
+		{"iqr", 101.5},
+		{"trim:20", 102},
+	} {
+		if err := setOutlierMode(tt.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		agg := aggregate(samples)
+		iv := agg.Intervals["ns/op"]
+
+		if agg.Speed != tt.expected || iv == nil || agg.Speed < iv.Low || agg.Speed > iv.High {
+			t.Errorf("Aggregating with interval and outlier mode %s: expected median %v within %#v, actual %v", tt.mode, tt.expected, iv, agg.Speed)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	colorAlways = "always"
+	colorNever  = "never"
+	colorAuto   = "auto"
+)
+
+var (
+	colorEnabled   = true
+	spinnerEnabled = true
+	// progressEnabled tells whether the progress of commands running benchmarks is shown on stderr
+	progressEnabled = true
+	// stderrColorEnabled tells whether warnings written to stderr are colored, which may be redirected separately
+	stderrColorEnabled = true
+)
+
+// setColor decides whether ANSI escape codes, the loading spinner and the progress are used.
+// In auto mode they are disabled if the stream they are written to is not a terminal or NO_COLOR is set
+// (see https://no-color.org), always and never only override the colors, the spinner and the progress
+// are never drawn into a file or pipe
+func setColor(mode string) error {
+	interactive := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	stderrInteractive := isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == ""
+	spinnerEnabled, progressEnabled = interactive, stderrInteractive
+
+	switch mode {
+	case colorAlways:
+		colorEnabled, stderrColorEnabled = true, true
+	case colorNever:
+		colorEnabled, stderrColorEnabled = false, false
+	case colorAuto, "":
+		colorEnabled = interactive
+		stderrColorEnabled = stderrInteractive
+	default:
+		return fmt.Errorf("invalid color mode %q, use %s, %s or %s", mode, colorAlways, colorNever, colorAuto)
+	}
+
+	return nil
+}
+
+func colorize(code, s string) string {
+	if !colorEnabled {
+		return s
+	}
+
+	return paint(code, s)
+}
+
+func paint(code, s string) string {
+	return fmt.Sprintf("\033[%sm%s\033[0m", code, s)
+}
@@ -0,0 +1,74 @@
+package prettybenchmarks
+
+import (
+	"os"
+	"testing"
+)
+
+func Test_setColor(t *testing.T) {
+	defer func() {
+		colorEnabled = true
+		spinnerEnabled = true
+		progressEnabled = true
+		stderrColorEnabled = true
+	}()
+
+	os.Setenv("NO_COLOR", "1")
+	defer os.Unsetenv("NO_COLOR")
+
+	for _, tt := range []struct {
+		mode     string
+		expected bool
+		err      bool
+	}{
+		{"always", true, false},
+		{"never", false, false},
+		{"auto", false, false},
+		{"", false, false},
+		{"sometimes", false, true},
+	} {
+		colorEnabled = !tt.expected
+		err := setColor(tt.mode)
+
+		if (err != nil) != tt.err {
+			t.Errorf("Setting color mode %q: expected error %v, got %v", tt.mode, tt.err, err)
+			continue
+		}
+
+		if !tt.err && colorEnabled != tt.expected {
+			t.Errorf("Setting color mode %q: expected colors %v, actual %v", tt.mode, tt.expected, colorEnabled)
+		}
+
+		if spinnerEnabled || progressEnabled {
+			t.Errorf("Setting color mode %q: expected spinner and progress to be disabled with NO_COLOR set", tt.mode)
+		}
+
+		if !tt.err && stderrColorEnabled != tt.expected {
+			t.Errorf("Setting color mode %q: expected colored warnings %v, actual %v", tt.mode, tt.expected, stderrColorEnabled)
+		}
+	}
+}
+
+func Test_colorize(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	if actual := bold("foo"); actual != "foo" {
+		t.Errorf("Formatting bold string without colors: expected %v, actual %v", "foo", actual)
+	}
+}
+
+func Test_isTerminal(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	if isTerminal(f) {
+		t.Errorf("Checking if %s is a terminal: expected false", os.DevNull)
+	}
+}
@@ -0,0 +1,440 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apcera/termtables"
+)
+
+// column describes one column of the rendered table: its header, how its cells are aligned
+// and formatted and whether it makes sense for the given benchmark at all
+type column struct {
+	name    string
+	header  func(bm *benchmark) string
+	align   alignment
+	format  func(bm *benchmark, r *result, first bool) string
+	visible func(bm *benchmark) bool
+
+	// perInput columns are repeated for every input when comparing benchmarks
+	perInput bool
+	// value returns the metric a delta is computed of, nil if the column does not hold a metric
+	value func(r *result) float64
+	// direction tells whether lower or higher values of the metric are better
+	direction direction
+	// unit is the unit of the metric the column holds, used to look up its confidence interval
+	unit string
+	// annotate returns markers appended to a cell, e.g. for unstable benchmarks
+	annotate func(bm *benchmark, r *result) string
+	// render formats v, a value of the metric, in the row of r. It renders values not held by r itself,
+	// like the geometric mean of value
+	render func(bm *benchmark, r *result, v float64) string
+	// geomean renders the cell of the geomean row of the given rows, by default the geometric mean of value
+	geomean func(bm *benchmark, rows []*result) string
+}
+
+type alignment int
+
+const (
+	alignRight alignment = iota
+	alignLeft
+)
+
+type direction int
+
+const (
+	unknownIsBetter direction = iota
+	lowerIsBetter
+	higherIsBetter
+)
+
+var baseColumns = []*column{
+	{
+		name: "name",
+		header: func(bm *benchmark) string {
+			var lenLongestName int
+
+			for name := range *bm.results {
+				if tmpLen := len(name); tmpLen > lenLongestName {
+					lenLongestName = tmpLen
+				}
+			}
+
+			// add padding to first col since alignment in header columns does not work
+			// padding of longest name + len("name") + 1 padding right
+			nameCol := make([]byte, 0, lenLongestName+4+1)
+			nameCol = append(nameCol, []byte("Name")...)
+
+			for i := 0; i < lenLongestName; i++ {
+				nameCol = append(nameCol, byte(32))
+			}
+
+			return string(nameCol)
+		},
+		align: alignLeft,
+		format: func(bm *benchmark, r *result, first bool) string {
+			if !first {
+				return ""
+			}
+
+			if hasFailed(r.Name) {
+				return bold(r.Name) + " " + red(failedMarker)
+			}
+			return bold(r.Name)
+		},
+		visible: always,
+	},
+	{
+		name:   "source",
+		header: staticHeader("Source"),
+		align:  alignLeft,
+		format: func(bm *benchmark, r *result, first bool) string {
+			return r.Source
+		},
+		visible: hasMultipleSources,
+	},
+	{
+		name:   "iterations",
+		header: staticHeader("Iterations"),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			if r.FnIterations == -1 {
+				return ""
+			}
+			return RenderInteger(fmtInt, r.FnIterations)
+		},
+		visible: func(bm *benchmark) bool { return bm.info.hasFnIterations },
+	},
+	{
+		name:   "procs",
+		header: staticHeader("Procs"),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			return RenderInteger(fmtInt, r.Procs)
+		},
+		visible: func(bm *benchmark) bool { return bm.info.hasMultipleProcs },
+	},
+	{
+		name:   "runs",
+		header: staticHeader("Runs"),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			if r.Runs < 0 {
+				return unknownValue
+			}
+			return RenderInteger(fmtInt, r.Runs)
+		},
+		visible:  always,
+		perInput: true,
+	},
+	{
+		name: "time",
+		header: func(bm *benchmark) string {
+			if unitMode != unitsGlobal {
+				return "time/op"
+			}
+			return bm.info.suggestedTiming + "/op"
+		},
+		align:     alignRight,
+		format:    formatTime,
+		render:    renderTime,
+		visible:   always,
+		perInput:  true,
+		value:     speed,
+		direction: lowerIsBetter,
+		unit:      "ns/op",
+		annotate:  noiseMarker,
+	},
+	{
+		name:      "element",
+		header:    staticHeader("time/element"),
+		align:     alignRight,
+		format:    formatPerElement,
+		render:    renderPerElement,
+		visible:   func(bm *benchmark) bool { return bm.info.hasFnIterations },
+		perInput:  true,
+		value:     perElement,
+		direction: lowerIsBetter,
+	},
+	{
+		name: "bytes",
+		header: func(bm *benchmark) string {
+			if byteMode == bytesRaw {
+				return "B/op"
+			}
+			return byteHeader(bm, bytesPerOp, "/op", "mem/op")
+		},
+		align: alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			if r.Bps < 0 {
+				return unknownValue
+			}
+			return renderBytesPerOp(bm, r, r.Bps)
+		},
+		render:    renderBytesPerOp,
+		visible:   func(bm *benchmark) bool { return bm.info.benchmemUsed },
+		perInput:  true,
+		value:     bytesPerOp,
+		direction: lowerIsBetter,
+		unit:      "B/op",
+	},
+	{
+		name:   "allocs",
+		header: staticHeader("allocations/op"),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			if r.Aps < 0 {
+				return unknownValue
+			}
+			return renderCount(r.Aps)
+		},
+		render: func(bm *benchmark, r *result, v float64) string {
+			return renderCount(v)
+		},
+		visible:   func(bm *benchmark) bool { return bm.info.benchmemUsed },
+		perInput:  true,
+		value:     allocsPerOp,
+		direction: lowerIsBetter,
+		unit:      "allocs/op",
+	},
+	{
+		name:   "discarded",
+		header: staticHeader("Discarded"),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			return RenderInteger(fmtInt, r.Discarded)
+		},
+		visible:  discardsSamples,
+		perInput: true,
+	},
+}
+
+// availableColumns returns the base columns and the derived ops/s followed by one column per custom metric
+// (as reported via b.ReportMetric) found in the benchmark and the throughput if it is only derived
+func availableColumns(bm *benchmark) []*column {
+	cols := make([]*column, 0, len(baseColumns)+len(bm.info.metrics)+2)
+	cols = append(cols, baseColumns...)
+	cols = append(cols, opsColumn)
+
+	for _, unit := range bm.info.metrics {
+		cols = append(cols, metricColumn(unit))
+	}
+
+	if !StringsContains(bm.info.metrics, "MB/s") && hasDerivedThroughput(bm) {
+		cols = append(cols, throughputColumn())
+	}
+
+	return cols
+}
+
+func metricColumn(unit string) *column {
+	if unit == "MB/s" {
+		return throughputColumn()
+	}
+
+	value := func(r *result) float64 {
+		return r.Metrics[unit]
+	}
+
+	render := func(bm *benchmark, r *result, v float64) string {
+		return renderMetric(fmtFloat, v, sampleValues(bm, r, value))
+	}
+
+	return &column{
+		name:   unit,
+		header: staticHeader(unit),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			v, ok := r.Metrics[unit]
+
+			if !ok {
+				return ""
+			}
+			return render(bm, r, v)
+		},
+		render:   render,
+		visible:  always,
+		perInput: true,
+		value:    value,
+		unit:     unit,
+	}
+}
+
+// throughputColumn renders the MB/s reported by benchmarks calling b.SetBytes or derived from the --bytes-per-op
+// rules, scaled like memory values
+func throughputColumn() *column {
+	return &column{
+		name: "MB/s",
+		header: func(bm *benchmark) string {
+			if byteMode == bytesRaw {
+				return "MB/s"
+			}
+			return byteHeader(bm, bytesPerSecond, "/s", "throughput")
+		},
+		align: alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			v := bytesPerSecond(r)
+
+			if v < 0 {
+				return ""
+			}
+			return renderThroughput(bm, r, v)
+		},
+		render:    renderThroughput,
+		visible:   always,
+		perInput:  true,
+		value:     bytesPerSecond,
+		direction: higherIsBetter,
+		unit:      "MB/s",
+	}
+}
+
+// renderBytesPerOp renders the memory value v in the row of r, see formatBytes
+func renderBytesPerOp(bm *benchmark, r *result, v float64) string {
+	if byteMode == bytesRaw {
+		return renderCount(v)
+	}
+	return renderBytes(bm, r, bytesPerOp, v, "/op")
+}
+
+// renderThroughput renders the throughput v in bytes per second in the row of r, raw values in MB/s
+func renderThroughput(bm *benchmark, r *result, v float64) string {
+	if byteMode == bytesRaw {
+		return renderMetric(fmtFloat, v/1e6, sampleValues(bm, r, bytesPerSecond))
+	}
+	return renderBytes(bm, r, bytesPerSecond, v, "/s")
+}
+
+// selectColumns picks the columns to render. An empty spec selects every visible column in
+// its default order, otherwise spec is a comma separated list of column names defining
+// which columns are shown and in which order
+func selectColumns(bm *benchmark, spec string) ([]*column, error) {
+	available := availableColumns(bm)
+	selected := make([]*column, 0, len(available))
+
+	if strings.TrimSpace(spec) == "" {
+		for _, c := range available {
+			if c.visible(bm) {
+				selected = append(selected, c)
+			}
+		}
+
+		return selected, nil
+	}
+
+	for _, name := range strings.Split(spec, ",") {
+		c := findColumn(available, strings.TrimSpace(name))
+
+		if c == nil {
+			return nil, fmt.Errorf("unknown column %q, available columns: %s", name, strings.Join(columnNames(available), ", "))
+		}
+
+		// explicitly selected columns are shown even without data, their cells stay empty or show unknownValue
+		selected = append(selected, c)
+	}
+
+	return selected, nil
+}
+
+func findColumn(cols []*column, name string) *column {
+	for _, c := range cols {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func columnNames(cols []*column) []string {
+	names := make([]string, 0, len(cols))
+
+	for _, c := range cols {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+func addTableHeader(t *termtables.Table, bm *benchmark, cols []*column) {
+	headers := make([]interface{}, 0, len(cols))
+
+	for _, c := range cols {
+		headers = append(headers, bold(c.header(bm)))
+	}
+
+	t.AddHeaders(headers...)
+}
+
+func addTableBody(t *termtables.Table, bm *benchmark, cols []*column) {
+	i := len(*bm.results)
+	sorted := make([]string, 0, i)
+
+	for name := range *bm.results {
+		sorted = append(sorted, name)
+	}
+
+	sort.Sort(sort.StringSlice(sorted))
+
+	for _, benchName := range sorted {
+		for j, r := range (*bm.results)[benchName] {
+			row := make([]interface{}, 0, len(cols))
+
+			for _, c := range cols {
+				row = append(row, formatCell(c, bm, r, j == 0))
+			}
+
+			t.AddRow(row...)
+		}
+
+		i--
+
+		if i > 0 {
+			t.AddSeparator()
+		}
+	}
+
+	for i, c := range cols {
+		if c.align == alignLeft {
+			t.SetAlign(termtables.AlignLeft, i+1)
+		}
+	}
+}
+
+// formatCell formats r's cell of column c followed by its confidence interval and markers
+func formatCell(c *column, bm *benchmark, r *result, first bool) string {
+	cell := c.format(bm, r, first)
+
+	if cell == "" {
+		return cell
+	}
+
+	if c.unit != "" {
+		cell += formatInterval(r, c.unit)
+	}
+
+	if c.annotate != nil {
+		cell += c.annotate(bm, r)
+	}
+
+	return cell
+}
+
+func bytesPerOp(r *result) float64 {
+	return r.Bps
+}
+
+func allocsPerOp(r *result) float64 {
+	return r.Aps
+}
+
+func staticHeader(s string) func(bm *benchmark) string {
+	return func(bm *benchmark) string {
+		return s
+	}
+}
+
+func always(bm *benchmark) bool {
+	return true
+}
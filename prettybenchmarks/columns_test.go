@@ -0,0 +1,87 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testsColumns = []struct {
+	input    [][]byte
+	spec     string
+	expected []string
+	err      bool
+}{
+	{
+		[][]byte{
+			[]byte("BenchmarkNewLargeReq-8      	   10000	    122245 ns/op\n"),
+		},
+		"",
+		[]string{"name", "runs", "time"},
+		false,
+	},
+	{
+		[][]byte{
+			[]byte("Benchmark_UnmarshalLargeReq_10-8    5000	    342400 ns/op	   60385 B/op	    1680 allocs/op\n"),
+			[]byte("Benchmark_UnmarshalLargeReq_10-4    5000	    342400 ns/op	   60385 B/op	    1680 allocs/op	12.50 widgets/op\n"),
+		},
+		"",
+		[]string{"name", "iterations", "procs", "runs", "time", "element", "bytes", "allocs", "widgets/op"},
+		false,
+	},
+	{
+		[][]byte{
+			[]byte("Benchmark_NewSmallReq-8      	  100000	     21618 ns/op	    2739 B/op	      45 allocs/op\n"),
+		},
+		"allocs, Name,time,iterations",
+		[]string{"allocs", "name", "time", "iterations"},
+		false,
+	},
+	{
+		[][]byte{
+			[]byte("Benchmark_NewSmallReq-8      	  100000	     21618 ns/op	    2739 B/op	      45 allocs/op\n"),
+		},
+		"name,foo",
+		nil,
+		true,
+	},
+	{
+		[][]byte{
+			[]byte("BenchmarkNewLargeReq-8      	   10000	    122245 ns/op\n"),
+		},
+		"name,bytes,element",
+		[]string{"name", "bytes", "element"},
+		false,
+	},
+}
+
+func Test_selectColumns(t *testing.T) {
+	for _, tt := range testsColumns {
+		cols, err := selectColumns(newBenchmark(tt.input), tt.spec)
+
+		if (err != nil) != tt.err {
+			t.Errorf("Selecting columns %q for input %s: expected error %v, got %v", tt.spec, tt.input, tt.err, err)
+			continue
+		}
+
+		var actual []string
+
+		if cols != nil {
+			actual = columnNames(cols)
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Selecting columns %q for input %s: expected %#v, actual %#v", tt.spec, tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func Test_newResultMetrics(t *testing.T) {
+	line := []byte("Benchmark_Encode-4    5000	    342400 ns/op	  100.25 MB/s	   60385 B/op	    1680 allocs/op	3.00 widgets/op\n")
+	expected := &result{"Encode", -1, 5000, float64(342400), 60385, 1680, 4, map[string]float64{"MB/s": 100.25, "widgets/op": 3}, "", 0, nil}
+
+	actual, err := newResult(line)
+
+	if err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Parsing %s: expected %#v, actual %#v (%v)", line, expected, actual, err)
+	}
+}
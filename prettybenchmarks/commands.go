@@ -0,0 +1,11 @@
+package prettybenchmarks
+
+// commands are the subcommands of pb, called with the arguments following the command name
+var commands = map[string]func(args []string) error{
+	"record":      recordCommand,
+	"history":     historyCommand,
+	"trend":       trendCommand,
+	"compare-git": compareGitCommand,
+	"run":         runCommand,
+	"ab":          abCommand,
+}
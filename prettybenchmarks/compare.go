@@ -0,0 +1,319 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// comparison holds N labelled inputs rendered side by side, one column per input and metric
+type comparison struct {
+	labels   []string
+	baseline int
+
+	// merged holds all samples of all inputs, each result's Source is its input's label
+	merged *benchmark
+	// rows holds one result per benchmark (name, iterations, procs) found in any input
+	rows *benchmark
+	// samples holds each benchmark's samples per input label
+	samples map[string]map[resultKey][]*result
+	// aggregated holds the median of each benchmark's samples per input label
+	aggregated map[string]map[resultKey]*result
+}
+
+func newComparison(inputs []*input, baseline string) (*comparison, error) {
+	if len(inputs) < 2 {
+		return nil, fmt.Errorf("compare mode needs at least 2 inputs, got %d", len(inputs))
+	}
+
+	labels := make([]string, 0, len(inputs))
+
+	for _, in := range inputs {
+		if StringsContains(labels, in.label) {
+			return nil, fmt.Errorf("input %q is given more than once, use label=path to tell inputs apart", in.label)
+		}
+
+		labels = append(labels, in.label)
+	}
+
+	base, err := findBaseline(labels, baseline)
+
+	if err != nil {
+		return nil, err
+	}
+
+	merged := newMergedBenchmark(inputs)
+	rows := make(results)
+	samples := make(map[string]map[resultKey][]*result)
+
+	for _, label := range labels {
+		samples[label] = make(map[resultKey][]*result)
+	}
+
+	for name, rs := range *merged.results {
+		for _, r := range rs {
+			k := r.key()
+
+			if !containsKey(rows[name], k) {
+				rows[name] = append(rows[name], &result{Name: r.Name, FnIterations: r.FnIterations, Procs: r.Procs})
+			}
+
+			samples[r.Source][k] = append(samples[r.Source][k], r)
+		}
+	}
+
+	for _, r := range rows {
+		sort.Stable(sortByFnIterations(r))
+	}
+
+	aggregated := make(map[string]map[resultKey]*result)
+
+	for label, byKey := range samples {
+		aggregated[label] = make(map[resultKey]*result)
+
+		for k, s := range byKey {
+			aggregated[label][k] = aggregate(s)
+		}
+	}
+
+	return &comparison{
+		labels:     labels,
+		baseline:   base,
+		merged:     merged,
+		rows:       &benchmark{info: merged.info, results: &rows},
+		samples:    samples,
+		aggregated: aggregated,
+	}, nil
+}
+
+// findBaseline resolves the baseline given as label or 1-based index, defaulting to the first input
+func findBaseline(labels []string, baseline string) (int, error) {
+	if baseline == "" {
+		return 0, nil
+	}
+
+	for i, label := range labels {
+		if label == baseline {
+			return i, nil
+		}
+	}
+
+	if i, err := strconv.Atoi(baseline); err == nil && i > 0 && i <= len(labels) {
+		return i - 1, nil
+	}
+
+	return 0, fmt.Errorf("unknown baseline %q", baseline)
+}
+
+func containsKey(rs []*result, k resultKey) bool {
+	for _, r := range rs {
+		if r.key() == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookup returns the aggregated result of the input with the given label for r's benchmark
+func (c *comparison) lookup(label string, r *result) *result {
+	return c.aggregated[label][r.key()]
+}
+
+// jsonResults returns the aggregated results of every input keyed by its label along with their geometric means
+// and the geometric mean changes against the baseline
+func (c *comparison) jsonResults() *jsonResults {
+	byLabel := make(map[string]results, len(c.labels))
+	geomeans := make(map[string]*result, len(c.labels))
+	deltas := make(map[string]map[string]float64, len(c.labels)-1)
+	rows := allResults(c.rows)
+
+	for i, label := range c.labels {
+		rs := make(results)
+
+		for _, agg := range c.aggregated[label] {
+			rs[agg.Name] = append(rs[agg.Name], agg)
+		}
+
+		for _, r := range rs {
+			sort.Stable(sortByFnIterations(r))
+		}
+
+		byLabel[label] = rs
+		geomeans[label] = geomeanResult(c.lookupAll(label, rows))
+
+		if i == c.baseline {
+			continue
+		}
+
+		deltas[label] = make(map[string]float64)
+
+		for _, unit := range append([]string{"ns/op", "B/op", "allocs/op"}, c.merged.info.metrics...) {
+			u := unit
+			value := func(r *result) float64 {
+				v, _ := metricValue(r, u)
+				return v
+			}
+
+			if ratio := c.geomeanRatio(label, rows, value); ratio > 0 {
+				deltas[label][unit] = (ratio - 1) * 100
+			}
+		}
+	}
+
+	return &jsonResults{Results: byLabel, Geomean: geomeans, Deltas: deltas}
+}
+
+// lookupAll returns the aggregated results of the input with the given label for all rows it has results for
+func (c *comparison) lookupAll(label string, rows []*result) []*result {
+	var aggs []*result
+
+	for _, r := range rows {
+		if agg := c.lookup(label, r); agg != nil {
+			aggs = append(aggs, agg)
+		}
+	}
+
+	return aggs
+}
+
+// geomeanRatio returns the geometric mean of the ratios of value of the input with the given label to the
+// baseline over all rows both have a positive value for, 0 if there are none
+func (c *comparison) geomeanRatio(label string, rows []*result, value func(r *result) float64) float64 {
+	var ratios []float64
+
+	for _, r := range rows {
+		base, other := c.lookup(c.labels[c.baseline], r), c.lookup(label, r)
+
+		if base == nil || other == nil || value(base) <= 0 || value(other) <= 0 {
+			continue
+		}
+
+		ratios = append(ratios, value(other)/value(base))
+	}
+
+	return geomean(ratios)
+}
+
+// columns repeats every per input column for each input and adds a delta column
+// against the baseline for every metric of the other inputs
+func (c *comparison) columns(cols []*column) []*column {
+	var expanded []*column
+
+	for _, col := range cols {
+		if !col.perInput {
+			expanded = append(expanded, col)
+			continue
+		}
+
+		for i, label := range c.labels {
+			expanded = append(expanded, c.inputColumn(col, label))
+
+			if i != c.baseline && col.value != nil {
+				expanded = append(expanded, c.deltaColumn(col, label))
+			}
+		}
+	}
+
+	return expanded
+}
+
+func (c *comparison) inputColumn(col *column, label string) *column {
+	return &column{
+		name: col.name,
+		header: func(bm *benchmark) string {
+			return col.header(c.merged) + " " + label
+		},
+		align: col.align,
+		format: func(bm *benchmark, r *result, first bool) string {
+			agg := c.lookup(label, r)
+
+			if agg == nil {
+				return ""
+			}
+			return formatCell(col, c.merged, agg, first)
+		},
+		visible: always,
+		geomean: func(bm *benchmark, rows []*result) string {
+			return geomeanCell(col, c.merged, c.lookupAll(label, rows))
+		},
+	}
+}
+
+func (c *comparison) deltaColumn(col *column, label string) *column {
+	return &column{
+		name:   col.name,
+		header: staticHeader("Δ " + label),
+		align:  alignRight,
+		format: func(bm *benchmark, r *result, first bool) string {
+			base, other := c.lookup(c.labels[c.baseline], r), c.lookup(label, r)
+
+			if base == nil || other == nil {
+				return ""
+			}
+
+			delta := formatDelta(col.value(base), col.value(other), col.direction)
+			x := values(c.samples[c.labels[c.baseline]][r.key()], col.value)
+			y := values(c.samples[label][r.key()], col.value)
+
+			// a single sample can't tell noise from change, so significance is only shown for repeated runs
+			if delta == "" || len(x) < 2 || len(y) < 2 {
+				return delta
+			}
+
+			p := mannWhitneyU(x, y)
+
+			if p >= alpha {
+				return "~ " + formatP(p)
+			}
+			return delta + " " + formatP(p)
+		},
+		visible: always,
+		geomean: func(bm *benchmark, rows []*result) string {
+			ratio := c.geomeanRatio(label, rows, col.value)
+
+			if ratio == 0 {
+				return ""
+			}
+			return formatDelta(1, ratio, col.direction)
+		},
+	}
+}
+
+func formatP(p float64) string {
+	return "(p=" + strconv.FormatFloat(p, 'f', 3, 64) + ")"
+}
+
+// formatDelta renders the relative change from old to new in percent, colored if it is known
+// whether the change is an improvement
+func formatDelta(old, new float64, d direction) string {
+	if old <= 0 || new < 0 {
+		return ""
+	}
+
+	delta := (new - old) / old * 100
+	s := RenderFloat("+"+fmtFloatUnit, delta) + "%"
+
+	switch {
+	case delta == 0 || d == unknownIsBetter:
+		return s
+	case (delta < 0) == (d == lowerIsBetter):
+		return green(s)
+	}
+
+	return red(s)
+}
+
+func printComparison(c *comparison) {
+	cols, err := selectColumns(c.rows, *columnsFlag)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	noteNoise(c.merged)
+	renderTable(c.rows, c.columns(cols))
+	fmt.Print(complexityReport(c.merged))
+}
@@ -0,0 +1,167 @@
+package prettybenchmarks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+)
+
+const workingTreeLabel = "working tree"
+
+// compareGitCommand implements 'pb compare-git <ref> [go test args]': it benchmarks ref, checked out into
+// a temporary git worktree, and the current working tree with the same go test arguments and compares them
+func compareGitCommand(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return errors.New("usage: pb compare-git <ref> [-- go test args]")
+	}
+
+	ref, testArgs := args[0], args[1:]
+
+	if len(testArgs) > 0 && testArgs[0] == "--" {
+		testArgs = testArgs[1:]
+	}
+
+	// the package directory relative to the repository root, to run go test in the same place of the worktree
+	prefix, err := gitOutput(".", "rev-parse", "--show-prefix")
+
+	if err != nil {
+		return err
+	}
+
+	var inputs []*input
+
+	err = withWorktree(".", ref, func(worktree string) error {
+		fmt.Fprintf(os.Stderr, "benchmarking %s\n", ref)
+
+		l, err := goTestBench(filepath.Join(worktree, filepath.FromSlash(prefix)), testArgs)
+
+		if err != nil {
+			return err
+		}
+
+		inputs = append(inputs, &input{ref, l})
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "benchmarking %s\n", workingTreeLabel)
+
+	l, err := goTestBench(".", testArgs)
+
+	if err != nil {
+		return err
+	}
+
+	inputs = append(inputs, &input{workingTreeLabel, l})
+
+	cmp, err := newComparison(inputs, "")
+
+	if err != nil {
+		return err
+	}
+
+	diagnostics := inputDiagnostics(inputs)
+	printDiagnostics(os.Stderr, diagnostics)
+
+	bench = cmp.merged
+	printComparison(cmp)
+	fmt.Println(footer())
+
+	return strictError(diagnostics)
+}
+
+// withWorktree checks ref out into a temporary linked worktree of the repository containing dir,
+// calls fn with its path and removes the worktree afterwards, even if pb is interrupted
+func withWorktree(dir, ref string, fn func(worktree string) error) error {
+	worktree, remove, err := addWorktree(dir, ref)
+
+	if err != nil {
+		return err
+	}
+
+	defer onInterrupt(remove)()
+
+	return fn(worktree)
+}
+
+// addWorktree checks ref out into a temporary linked worktree of the repository containing dir
+// and returns its path along with a function removing it again
+func addWorktree(dir, ref string) (string, func(), error) {
+	tmp, err := ioutil.TempDir("", "pb-compare-git")
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	worktree := filepath.Join(tmp, "tree")
+
+	if _, err := gitOutput(dir, "worktree", "add", "--detach", worktree, ref); err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, err
+	}
+
+	return worktree, func() {
+		gitOutput(dir, "worktree", "remove", "--force", worktree)
+		os.RemoveAll(tmp)
+	}, nil
+}
+
+// onInterrupt calls cleanup and exits if pb is interrupted until the returned function is called,
+// which calls cleanup itself
+func onInterrupt(cleanup func()) func() {
+	interrupted := make(chan os.Signal, 1)
+	done := make(chan bool)
+	signal.Notify(interrupted, os.Interrupt)
+
+	go func() {
+		select {
+		case <-interrupted:
+			cleanup()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(interrupted)
+		close(done)
+		cleanup()
+	}
+}
+
+// gitOutput runs git in dir and returns its trimmed output, errors include what git printed to stderr
+func gitOutput(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// goTestBench runs go test with the given arguments in dir, preceded by the defaults of 'pb run'
+func goTestBench(dir string, args []string) ([][]byte, error) {
+	return goTest(dir, benchArgs(args))
+}
+
+// benchArgs puts defaultRunArgs before go test arguments, which may override them just like with 'pb run'
+func benchArgs(args []string) []string {
+	return append(append([]string{}, defaultRunArgs...), args...)
+}
@@ -0,0 +1,78 @@
+package prettybenchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_benchArgs(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		expected []string
+	}{
+		{nil, []string{"-run=^$", "-bench=.", "-benchmem"}},
+		{[]string{"./..."}, []string{"-run=^$", "-bench=.", "-benchmem", "./..."}},
+		{[]string{"-bench=Foo", "-run=Bar"}, []string{"-run=^$", "-bench=.", "-benchmem", "-bench=Foo", "-run=Bar"}},
+		{[]string{"-bench", "Foo"}, []string{"-run=^$", "-bench=.", "-benchmem", "-bench", "Foo"}},
+	} {
+		if actual := benchArgs(tt.args); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("Adding bench args to %v: expected %v, actual %v", tt.args, tt.expected, actual)
+		}
+	}
+}
+
+func Test_withWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "pb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=pb", "-c", "user.email=pb@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if _, err := gitOutput(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var checkedOut string
+
+	err = withWorktree(dir, "HEAD", func(worktree string) error {
+		checkedOut = worktree
+		commit, _, err := gitHead(worktree)
+
+		if err != nil || commit == "" {
+			t.Errorf("Checking out worktree: expected commit, actual %q (%v)", commit, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(checkedOut); !os.IsNotExist(err) {
+		t.Errorf("Removing worktree %s: still exists", checkedOut)
+	}
+
+	if list, _ := gitOutput(dir, "worktree", "list"); strings.Contains(list, checkedOut) {
+		t.Errorf("Removing worktree: still listed in %q", list)
+	}
+
+	if err := withWorktree(dir, "does-not-exist", func(string) error { return nil }); err == nil {
+		t.Errorf("Checking out unknown ref: expected error")
+	}
+}
@@ -0,0 +1,162 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testInputs = []*input{
+	{"old", [][]byte{
+		[]byte("Benchmark_Foo_10-8 	 100	 1000 ns/op	 100 B/op	 2 allocs/op\n"),
+		[]byte("Benchmark_Foo_10-8 	 100	 1200 ns/op	 100 B/op	 2 allocs/op\n"),
+		[]byte("Benchmark_Foo_10-8 	 100	 1100 ns/op	 100 B/op	 2 allocs/op\n"),
+		[]byte("Benchmark_Bar-8 	 100	 500 ns/op	 100 B/op	 2 allocs/op\n"),
+	}},
+	{"new", [][]byte{
+		[]byte("Benchmark_Foo_10-8 	 100	 880 ns/op	 150 B/op	 2 allocs/op\n"),
+		[]byte("Benchmark_Foo_100-8 	 100	 8000 ns/op	 150 B/op	 2 allocs/op\n"),
+	}},
+}
+
+func Test_newComparison(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	cmp, err := newComparison(testInputs, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []int
+
+	for _, r := range (*cmp.rows.results)["Foo"] {
+		rows = append(rows, r.FnIterations)
+	}
+
+	if expected := []int{10, 100}; !reflect.DeepEqual(rows, expected) {
+		t.Errorf("Comparing inputs: expected rows %v, actual %v", expected, rows)
+	}
+
+	foo := (*cmp.rows.results)["Foo"][0]
+
+	if actual := cmp.lookup("old", foo).Speed; actual != 1100 {
+		t.Errorf("Comparing inputs: expected median 1100, actual %v", actual)
+	}
+
+	cols, err := selectColumns(cmp.rows, "name,time,bytes")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cells []string
+
+	for _, c := range cmp.columns(cols) {
+		cells = append(cells, c.format(cmp.rows, foo, true))
+	}
+
+	if expected := []string{"Foo", "1.100 ⚠", "0.880", "-20.00%", "100", "150", "+50.00%"}; !reflect.DeepEqual(cells, expected) {
+		t.Errorf("Comparing inputs: expected cells %#v, actual %#v", expected, cells)
+	}
+
+	if _, err := newComparison(testInputs[:1], ""); err == nil {
+		t.Errorf("Comparing a single input: expected error")
+	}
+}
+
+func Test_findBaseline(t *testing.T) {
+	labels := []string{"go1.21", "go1.22", "tip"}
+
+	for _, tt := range []struct {
+		baseline string
+		expected int
+		err      bool
+	}{
+		{"", 0, false},
+		{"tip", 2, false},
+		{"2", 1, false},
+		{"4", 0, true},
+		{"go1.20", 0, true},
+	} {
+		actual, err := findBaseline(labels, tt.baseline)
+
+		if (err != nil) != tt.err || actual != tt.expected {
+			t.Errorf("Finding baseline %q: expected %v (error %v), actual %v (%v)", tt.baseline, tt.expected, tt.err, actual, err)
+		}
+	}
+}
+
+func Test_formatDelta(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = true
+
+	for _, tt := range []struct {
+		old, new  float64
+		direction direction
+		expected  string
+	}{
+		{100, 90, lowerIsBetter, green("-10.00%")},
+		{100, 110, lowerIsBetter, red("+10.00%")},
+		{100, 110, higherIsBetter, green("+10.00%")},
+		{100, 110, unknownIsBetter, "+10.00%"},
+		{100, 100, lowerIsBetter, "0.00%"},
+		{0, 100, lowerIsBetter, ""},
+		{100, -1, lowerIsBetter, ""},
+	} {
+		if actual := formatDelta(tt.old, tt.new, tt.direction); actual != tt.expected {
+			t.Errorf("Formatting delta %v -> %v: expected %q, actual %q", tt.old, tt.new, tt.expected, actual)
+		}
+	}
+}
+
+func Test_deltaSignificance(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	inputs := []*input{
+		{"old", [][]byte{
+			[]byte("Benchmark_Foo-8 	 100	 1000 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 1010 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 1020 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 1030 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 1040 ns/op	 100 B/op	 2 allocs/op\n"),
+		}},
+		{"new", [][]byte{
+			[]byte("Benchmark_Foo-8 	 100	 900 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 910 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 920 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 930 ns/op	 100 B/op	 2 allocs/op\n"),
+			[]byte("Benchmark_Foo-8 	 100	 940 ns/op	 100 B/op	 2 allocs/op\n"),
+		}},
+	}
+
+	cmp, err := newComparison(inputs, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := selectColumns(cmp.rows, "time,bytes")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foo := (*cmp.rows.results)["Foo"][0]
+
+	var deltas []string
+
+	for _, c := range cmp.columns(cols) {
+		if c.header(cmp.rows) == "Δ new" {
+			deltas = append(deltas, c.format(cmp.rows, foo, true))
+		}
+	}
+
+	if expected := []string{"-9.80% (p=0.008)", "~ (p=1.000)"}; !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("Testing significance: expected deltas %#v, actual %#v", expected, deltas)
+	}
+}
@@ -0,0 +1,200 @@
+package prettybenchmarks
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// minComplexityPoints is the number of different FnIterations needed to fit a complexity
+const minComplexityPoints = 3
+
+// complexity is a model of how the time of a benchmark grows with n, its FnIterations
+type complexity struct {
+	name string
+	f    func(n float64) float64
+}
+
+// complexities are fitted in this order, on equal fits the simpler model wins
+var complexities = []*complexity{
+	{"O(1)", func(n float64) float64 { return 1 }},
+	{"O(log n)", func(n float64) float64 { return math.Log2(n) }},
+	{"O(n)", func(n float64) float64 { return n }},
+	{"O(n log n)", func(n float64) float64 { return n * math.Log2(n) }},
+	{"O(n²)", func(n float64) float64 { return n * n }},
+}
+
+// fit is the result of fitting t = c * f(n) to the times of a benchmark
+type fit struct {
+	complexity *complexity
+	c          float64
+	rSquared   float64
+}
+
+// fitComplexity fits every complexity to the times t measured for the sizes n by least squares and returns the
+// best one, nil if there are too few sizes
+func fitComplexity(n, t []float64) *fit {
+	if len(n) < minComplexityPoints {
+		return nil
+	}
+
+	m := mean(t)
+
+	var ssTotal float64
+
+	for _, v := range t {
+		ssTotal += (v - m) * (v - m)
+	}
+
+	var best *fit
+
+	for _, cx := range complexities {
+		var sumFT, sumFF float64
+
+		for i := range n {
+			f := cx.f(n[i])
+			sumFT += f * t[i]
+			sumFF += f * f
+		}
+
+		if sumFF == 0 {
+			continue
+		}
+
+		c := sumFT / sumFF
+
+		var ssResidual float64
+
+		for i := range n {
+			d := t[i] - c*cx.f(n[i])
+			ssResidual += d * d
+		}
+
+		rSquared := 1.0
+
+		if ssTotal > 0 {
+			rSquared = 1 - ssResidual/ssTotal
+		}
+
+		if best == nil || rSquared > best.rSquared+1e-9 {
+			best = &fit{cx, c, rSquared}
+		}
+	}
+
+	return best
+}
+
+// complexityReport returns the best fitting complexity of every benchmark of bm run with at least
+// minComplexityPoints different FnIterations, one line each
+func complexityReport(bm *benchmark) string {
+	if !bm.info.hasFnIterations {
+		return ""
+	}
+
+	names := make([]string, 0, len(*bm.results))
+
+	for name := range *bm.results {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var report []string
+
+	for _, name := range names {
+		type group struct {
+			procs  int
+			source string
+		}
+
+		var groups []group
+
+		samples := make(map[group]map[int][]*result)
+
+		for _, r := range (*bm.results)[name] {
+			if r.FnIterations <= 0 || r.Speed < 0 {
+				continue
+			}
+
+			g := group{r.Procs, r.Source}
+
+			if samples[g] == nil {
+				samples[g] = make(map[int][]*result)
+				groups = append(groups, g)
+			}
+
+			samples[g][r.FnIterations] = append(samples[g][r.FnIterations], r)
+		}
+
+		for _, g := range groups {
+			var sizes []int
+
+			for size := range samples[g] {
+				sizes = append(sizes, size)
+			}
+
+			sort.Ints(sizes)
+
+			n := make([]float64, 0, len(sizes))
+			t := make([]float64, 0, len(sizes))
+
+			for _, size := range sizes {
+				n = append(n, float64(size))
+				t = append(t, medianOf(samples[g][size], speed))
+			}
+
+			best := fitComplexity(n, t)
+
+			if best == nil {
+				continue
+			}
+
+			label := displayName(name, -1, g.procs)
+
+			if hasMultipleSources(bm) {
+				label += " (" + g.source + ")"
+			}
+
+			line := label + ": " + best.complexity.name
+
+			// a constant explains none of the variance, its R² is always 0
+			if best.complexity != complexities[0] {
+				line += ", R² " + RenderFloat(fmtFloat, best.rSquared)
+			}
+
+			report = append(report, line)
+		}
+	}
+
+	if len(report) == 0 {
+		return ""
+	}
+
+	return bold("Complexity:") + "\n" + strings.Join(report, "\n") + "\n"
+}
+
+// perElement returns r's time per element, i.e. its ns/op divided by its FnIterations, -1 if unknown
+func perElement(r *result) float64 {
+	if r.FnIterations <= 0 || r.Speed < 0 {
+		return -1
+	}
+
+	return r.Speed / float64(r.FnIterations)
+}
+
+func formatPerElement(bm *benchmark, r *result, first bool) string {
+	v := perElement(r)
+
+	if v < 0 {
+		return ""
+	}
+
+	return renderPerElement(bm, r, v)
+}
+
+// renderPerElement renders the time per element v in ns in the row of r, in a unit suitable for v
+func renderPerElement(bm *benchmark, r *result, v float64) string {
+	unit := suitableTiming(v)
+
+	return renderMetric(fmtTimeUnit, v/timeDivisors[unit], sampleValues(bm, r, perElement)) + " " + unit
+}
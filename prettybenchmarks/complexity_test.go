@@ -0,0 +1,67 @@
+package prettybenchmarks
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_fitComplexity(t *testing.T) {
+	n := []float64{10, 100, 1000, 10000}
+
+	for _, tt := range []struct {
+		f        func(n float64) float64
+		expected string
+	}{
+		{func(n float64) float64 { return 50 }, "O(1)"},
+		{func(n float64) float64 { return 20 * math.Log2(n) }, "O(log n)"},
+		{func(n float64) float64 { return 3 * n }, "O(n)"},
+		{func(n float64) float64 { return 2 * n * math.Log2(n) }, "O(n log n)"},
+		{func(n float64) float64 { return n * n / 10 }, "O(n²)"},
+	} {
+		t1 := make([]float64, len(n))
+
+		for i := range n {
+			// add some noise
+			t1[i] = tt.f(n[i]) * (1 + 0.02*float64(i%2))
+		}
+
+		best := fitComplexity(n, t1)
+
+		if best == nil || best.complexity.name != tt.expected {
+			t.Errorf("Fitting %v: expected %s, actual %#v", t1, tt.expected, best)
+			continue
+		}
+
+		// a constant explains none of the variance
+		if tt.expected != "O(1)" && best.rSquared < 0.99 {
+			t.Errorf("Fitting %v: expected R² close to 1, actual %v", t1, best.rSquared)
+		}
+	}
+
+	if best := fitComplexity(n[:2], []float64{1, 2}); best != nil {
+		t.Errorf("Fitting 2 points: expected no fit, actual %#v", best)
+	}
+}
+
+func Test_complexityReport(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Sort_10-8 	 100	 100 ns/op\n"),
+		[]byte("Benchmark_Sort_100-8 	 100	 1000 ns/op\n"),
+		[]byte("Benchmark_Sort_1000-8 	 100	 10000 ns/op\n"),
+		[]byte("Benchmark_Sort_1000-8 	 100	 10200 ns/op\n"),
+		[]byte("Benchmark_Small_10-8 	 100	 100 ns/op\n"),
+		[]byte("Benchmark_Small_100-8 	 100	 1000 ns/op\n"),
+	})
+
+	if expected := "Complexity:\nSort-8: O(n), R² 1.000\n"; complexityReport(bm) != expected {
+		t.Errorf("Reporting complexity: expected %q, actual %q", expected, complexityReport(bm))
+	}
+
+	if actual := formatPerElement(bm, (*bm.results)["Sort"][0], true); actual != "10.00 ns" {
+		t.Errorf("Formatting time per element: expected %q, actual %q", "10.00 ns", actual)
+	}
+}
@@ -0,0 +1,95 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// unknownValue is rendered in place of values which are missing or could not be parsed
+const unknownValue = "?"
+
+// strictMode makes pb exit with a non-zero status if any benchmark line holds malformed values
+var strictMode bool
+
+// diagnostic describes a malformed value of a benchmark line, located by its 1-based line and byte column
+type diagnostic struct {
+	source  string
+	line    int
+	column  int
+	message string
+}
+
+func (d *diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.source, d.line, d.column, d.message)
+}
+
+// fieldColumns returns the 1-based byte column of each of parts, the fields s was split into
+func fieldColumns(s string, parts []string) []int {
+	columns := make([]int, len(parts))
+	pos := 0
+
+	for i, part := range parts {
+		offset := strings.Index(s[pos:], part)
+
+		if offset < 0 {
+			offset = 0
+		}
+
+		columns[i] = pos + offset + 1
+		pos += offset + len(part)
+	}
+
+	return columns
+}
+
+// inputDiagnostics parses the lines of all inputs and returns the diagnostics of their benchmark lines
+func inputDiagnostics(inputs []*input) []*diagnostic {
+	var diags []*diagnostic
+
+	for _, in := range inputs {
+		p := &lineParser{}
+
+		for _, l := range in.lines {
+			p.parse(l)
+		}
+
+		for _, d := range p.diagnostics {
+			d.source = in.label
+		}
+
+		diags = append(diags, p.diagnostics...)
+	}
+
+	return diags
+}
+
+// printDiagnostics writes one warning per diagnostic to w, usually stderr
+func printDiagnostics(w io.Writer, diags []*diagnostic) {
+	label := "warning:"
+
+	if stderrColorEnabled {
+		label = paint("1;33", label)
+	}
+
+	for _, d := range diags {
+		fmt.Fprintln(w, label+" "+d.String())
+	}
+}
+
+// exitOnDiagnostics exits with status 1 in strict mode if there are any diagnostics
+func exitOnDiagnostics(diags []*diagnostic) {
+	if strictError(diags) != nil {
+		os.Exit(1)
+	}
+}
+
+// strictError returns an error in strict mode if there are any diagnostics, for commands reporting failures as errors
+func strictError(diags []*diagnostic) error {
+	if strictMode && len(diags) > 0 {
+		return fmt.Errorf("%d malformed benchmark values", len(diags))
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package prettybenchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_inputDiagnostics(t *testing.T) {
+	inputs := []*input{
+		{"old.txt", [][]byte{
+			[]byte("goos: linux"),
+			[]byte("BenchmarkA-8   \t 1000\t      1052 ns/op\t  12 B/op\t x allocs/op"),
+		}},
+		{"new.txt", [][]byte{
+			[]byte("BenchmarkB-8   \t abc\t      1o5 ns/op"),
+			[]byte("BenchmarkC"),
+			[]byte("    log output 100\t 10 ns/op\t 2.5e widgets/op"),
+			[]byte("BenchmarkD-8   \t 1000\t      1052 ns/op"),
+		}},
+	}
+
+	expected := []string{
+		`old.txt:2:51: invalid allocs/op "x"`,
+		`new.txt:1:18: invalid runs "abc"`,
+		`new.txt:1:28: invalid ns/op "1o5"`,
+		`new.txt:3:31: invalid widgets/op "2.5e"`,
+	}
+
+	var actual []string
+
+	for _, d := range inputDiagnostics(inputs) {
+		actual = append(actual, d.String())
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Diagnosing inputs: expected %q, actual %q", expected, actual)
+	}
+}
+
+func Test_fieldColumns(t *testing.T) {
+	s := "BenchmarkA  \t10\t 5 ns/op"
+	expected := []int{1, 14, 18, 20}
+
+	if actual := fieldColumns(s, regExByWhitespace.Split(s, -1)); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Locating fields of %q: expected %v, actual %v", s, expected, actual)
+	}
+}
+
+func Test_unknownValues(t *testing.T) {
+	r, err := newResult([]byte("BenchmarkA-8\tabc\t1o5 ns/op\t1 B/op\tx allocs/op"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &benchmark{results: &results{"A": {r}}, info: &benchmarkInfo{suggestedTiming: "ns", benchmemUsed: true}}
+
+	for _, name := range []string{"runs", "time", "allocs"} {
+		if actual := findColumn(baseColumns, name).format(bm, r, true); actual != unknownValue {
+			t.Errorf("Rendering malformed %s: expected %q, actual %q", name, unknownValue, actual)
+		}
+	}
+}
+
+func Test_strictError(t *testing.T) {
+	defer func() { strictMode = false }()
+
+	diags := []*diagnostic{{"go test", 2, 18, `invalid runs "abc"`}}
+
+	for _, tt := range []struct {
+		strict bool
+		diags  []*diagnostic
+		err    bool
+	}{
+		{false, diags, false},
+		{true, nil, false},
+		{true, diags, true},
+	} {
+		strictMode = tt.strict
+
+		if err := strictError(tt.diags); (err != nil) != tt.err {
+			t.Errorf("Checking %d diagnostics in strict mode %v: expected error %v, actual %v", len(tt.diags), tt.strict, tt.err, err)
+		}
+	}
+}
+
+func Test_recordStrict(t *testing.T) {
+	defer func() { strictMode = false }()
+
+	dir, err := ioutil.TempDir("", "pb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bad.txt")
+
+	if err := ioutil.WriteFile(path, []byte("BenchmarkA-8\tabc\t10 ns/op\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	history := *historyFlag
+	*historyFlag = filepath.Join(dir, "history")
+	defer func() { *historyFlag = history }()
+
+	strictMode = true
+
+	if err := recordCommand([]string{path}); err == nil {
+		t.Errorf("Recording malformed results in strict mode: expected an error")
+	}
+
+	if _, err := os.Stat(*historyFlag); !os.IsNotExist(err) {
+		t.Errorf("Recording malformed results in strict mode: expected no history, got %v", err)
+	}
+}
@@ -16,7 +16,8 @@ Examples of format strings, given n = 12345.6789:
 "#\u202F###,##" => "12 345,67"
 "#.###,###### => 12.345,678900
 "" (aka default format) => 12,345.67
-The highest precision allowed is 9 digits after the decimal symbol.
+Any precision after the decimal symbol is supported.
+Use ParseNumberFormat to validate a format once and get an error instead of a panic.
 There is also a version for integer number, RenderInteger(),
 which is convenient for calls within template.
 I didn't feel it was worth to publish a library just for this piece
@@ -26,46 +27,113 @@ of code, hence the snippet. Feel free to reuse as you wish.
 package prettybenchmarks
 
 import (
+	"errors"
 	"math"
 	"strconv"
+	"strings"
 )
 
-var renderFloatPrecisionMultipliers = [10]float64{
-	1,
-	10,
-	100,
-	1000,
-	10000,
-	100000,
-	1000000,
-	10000000,
-	100000000,
-	1000000000,
+// NumberFormat is a validated number format, see ParseNumberFormat
+type NumberFormat struct {
+	significant int
+	trimmed     bool
+	precision   int
+	decimalStr  string
+	thousandStr string
+	positiveStr string
+	negativeStr string
 }
 
-var renderFloatPrecisionRounders = [10]float64{
-	0.5,
-	0.05,
-	0.005,
-	0.0005,
-	0.00005,
-	0.000005,
-	0.0000005,
-	0.00000005,
-	0.000000005,
-	0.0000000005,
+// ErrPositiveSign is returned by ParseNumberFormat if the format starts with a directive other than '+'
+var ErrPositiveSign = errors.New("invalid positive sign directive")
+
+// ErrThousandsSeparator is returned by ParseNumberFormat if the thousands separator is not followed by 3 digit-specifiers
+var ErrThousandsSeparator = errors.New("thousands separator directive must be followed by 3 digit-specifiers")
+
+// ErrTooManyDirectives is returned by ParseNumberFormat if the format contains more than a sign, thousands and decimal directive
+var ErrTooManyDirectives = errors.New("too many formatting directives")
+
+//ParseNumberFormat validates the given format (see RenderFloat for examples)
+func ParseNumberFormat(format string) (NumberFormat, error) {
+	// default format
+	f := NumberFormat{
+		precision:   2,
+		decimalStr:  ".",
+		thousandStr: ",",
+		positiveStr: "",
+		negativeStr: "-",
+	}
+
+	if len(format) == 0 {
+		return f, nil
+	}
+
+	// If there is an explicit format directive,
+	// then default values are these:
+	f.precision = 9
+	f.thousandStr = ""
+
+	// collect indices of meaningful formatting directives
+	formatDirectiveChars := []rune(format)
+	formatDirectiveIndices := make([]int, 0)
+	for i, char := range formatDirectiveChars {
+		if char != '#' && char != '0' {
+			formatDirectiveIndices = append(formatDirectiveIndices, i)
+		}
+	}
+
+	if len(formatDirectiveIndices) == 0 {
+		return f, nil
+	}
+
+	// Directive at index 0:
+	//   Must be a '+'
+	//   Raise an error if not the case
+	// index: 0123456789
+	//        +0.000,000
+	//        +000,000.0
+	//        +0000.00
+	//        +0000
+	if formatDirectiveIndices[0] == 0 {
+		if formatDirectiveChars[formatDirectiveIndices[0]] != '+' {
+			return f, ErrPositiveSign
+		}
+		f.positiveStr = "+"
+		formatDirectiveIndices = formatDirectiveIndices[1:]
+	}
+
+	// Two directives:
+	//   First is thousands separator
+	//   Raise an error if not followed by 3-digit
+	// 0123456789
+	// 0.000,000
+	// 000,000.00
+	if len(formatDirectiveIndices) == 2 {
+		if (formatDirectiveIndices[1] - formatDirectiveIndices[0]) != 4 {
+			return f, ErrThousandsSeparator
+		}
+		f.thousandStr = string(formatDirectiveChars[formatDirectiveIndices[0]])
+		formatDirectiveIndices = formatDirectiveIndices[1:]
+	}
+
+	// One directive:
+	//   Directive is decimal separator
+	//   The number of digit-specifier following the separator indicates wanted precision
+	// 0123456789
+	// 0.00
+	// 000,0000
+	if len(formatDirectiveIndices) == 1 {
+		f.decimalStr = string(formatDirectiveChars[formatDirectiveIndices[0]])
+		f.precision = len(formatDirectiveChars) - formatDirectiveIndices[0] - 1
+	} else if len(formatDirectiveIndices) > 1 {
+		return f, ErrTooManyDirectives
+	}
+
+	return f, nil
 }
 
-//RenderFloat formats a given integer n according to the provided format
-//Examples of format strings for given n = 12345.6789:
-//    "#,###.##" => "12,345.67"
-//    "#,###." => "12,345"
-//    "#,###" => "12345,678"
-//    "#\u202F###,##" => "12 345,67"
-//    "#.###,###### => 12.345,678900
-//    "" (aka default format) => 12,345.67
-//Author: https://github.com/gorhill, Source: https://gist.github.com/gorhill/5285193
-func RenderFloat(format string, n float64) string {
+//Format renders n according to the number format
+func (f NumberFormat) Format(n float64) string {
 	// Special cases:
 	//   NaN = "NaN"
 	//   +Inf = "+Infinity"
@@ -80,111 +148,108 @@ func RenderFloat(format string, n float64) string {
 		return "-Infinity"
 	}
 
-	// default format
-	precision := 2
-	decimalStr := "."
-	thousandStr := ","
-	positiveStr := ""
-	negativeStr := "-"
-
-	if len(format) > 0 {
-		// If there is an explicit format directive,
-		// then default values are these:
-		precision = 9
-		thousandStr = ""
-
-		// collect indices of meaningful formatting directives
-		formatDirectiveChars := []rune(format)
-		formatDirectiveIndices := make([]int, 0)
-		for i, char := range formatDirectiveChars {
-			if char != '#' && char != '0' {
-				formatDirectiveIndices = append(formatDirectiveIndices, i)
-			}
-		}
+	// with significant digits, the precision depends on the magnitude of n
+	if f.significant > 0 {
+		if n == 0 {
+			f.precision = f.significant - 1
+		} else {
+			n, _ = strconv.ParseFloat(strconv.FormatFloat(n, 'e', f.significant-1, 64), 64)
+			f.precision = f.significant - 1 - int(math.Floor(math.Log10(math.Abs(n))))
 
-		if len(formatDirectiveIndices) > 0 {
-			// Directive at index 0:
-			//   Must be a '+'
-			//   Raise an error if not the case
-			// index: 0123456789
-			//        +0.000,000
-			//        +000,000.0
-			//        +0000.00
-			//        +0000
-			if formatDirectiveIndices[0] == 0 {
-				if formatDirectiveChars[formatDirectiveIndices[0]] != '+' {
-					panic("RenderFloat(): invalid positive sign directive")
-				}
-				positiveStr = "+"
-				formatDirectiveIndices = formatDirectiveIndices[1:]
+			if f.precision < 0 {
+				f.precision = 0
 			}
+		}
+	}
 
-			// Two directives:
-			//   First is thousands separator
-			//   Raise an error if not followed by 3-digit
-			// 0123456789
-			// 0.000,000
-			// 000,000.00
-			if len(formatDirectiveIndices) == 2 {
-				if (formatDirectiveIndices[1] - formatDirectiveIndices[0]) != 4 {
-					panic("RenderFloat(): thousands separator directive must be followed by 3 digit-specifiers")
-				}
-				thousandStr = string(formatDirectiveChars[formatDirectiveIndices[0]])
-				formatDirectiveIndices = formatDirectiveIndices[1:]
-			}
+	// round to the wanted precision, then split number into integer and fractional parts
+	digits := strconv.FormatFloat(math.Abs(n), 'f', f.precision, 64)
+	intStr, fracStr := digits, ""
 
-			// One directive:
-			//   Directive is decimal separator
-			//   The number of digit-specifier following the separator indicates wanted precision
-			// 0123456789
-			// 0.00
-			// 000,0000
-			if len(formatDirectiveIndices) == 1 {
-				decimalStr = string(formatDirectiveChars[formatDirectiveIndices[0]])
-				precision = len(formatDirectiveChars) - formatDirectiveIndices[0] - 1
-			}
-		}
+	if i := strings.IndexByte(digits, '.'); i > -1 {
+		intStr, fracStr = digits[:i], digits[i+1:]
 	}
 
-	// generate sign part
+	// generate sign part, values rounding to zero don't get one
 	var signStr string
-	if n >= 0.000000001 {
-		signStr = positiveStr
-	} else if n <= -0.000000001 {
-		signStr = negativeStr
-		n = -n
-	} else {
-		signStr = ""
-		n = 0.0
+	if strings.Trim(digits, "0.") != "" {
+		if n > 0 {
+			signStr = f.positiveStr
+		} else {
+			signStr = f.negativeStr
+		}
 	}
 
-	// split number into integer and fractional parts
-	intf, fracf := math.Modf(n + renderFloatPrecisionRounders[precision])
-
-	// generate integer part string
-	intStr := strconv.Itoa(int(intf))
-
 	// add thousand separator if required
-	if len(thousandStr) > 0 {
+	if len(f.thousandStr) > 0 {
 		for i := len(intStr); i > 3; {
 			i -= 3
-			intStr = intStr[:i] + thousandStr + intStr[i:]
+			intStr = intStr[:i] + f.thousandStr + intStr[i:]
 		}
 	}
 
+	if f.trimmed {
+		fracStr = strings.TrimRight(fracStr, "0")
+	}
+
 	// no fractional part, we can leave now
-	if precision == 0 {
+	if f.precision == 0 || fracStr == "" {
 		return signStr + intStr
 	}
 
-	// generate fractional part
-	fracStr := strconv.Itoa(int(fracf * renderFloatPrecisionMultipliers[precision]))
-	// may need padding
-	if len(fracStr) < precision {
-		fracStr = "000000000000000"[:precision-len(fracStr)] + fracStr
+	return signStr + intStr + f.decimalStr + fracStr
+}
+
+//Significant returns a copy of the number format rendering the given number of significant digits
+//instead of a fixed precision, digits < 1 switch back to the fixed precision
+//Examples for format "#,###.##" and 3 significant digits:
+//    12345.6789 => "12,300"
+//    109.805 => "110"
+//    0.0012345 => "0.00123"
+func (f NumberFormat) Significant(digits int) NumberFormat {
+	f.significant = digits
+	return f
+}
+
+//Trimmed returns a copy of the number format omitting trailing zeros of the fractional part,
+//and the decimal separator if no fractional digits remain
+//Examples for format "#,###.##":
+//    12.50 => "12.5"
+//    12.00 => "12"
+func (f NumberFormat) Trimmed() NumberFormat {
+	f.trimmed = true
+	return f
+}
+
+//RenderFloat formats a given integer n according to the provided format
+//Examples of format strings for given n = 12345.6789:
+//    "#,###.##" => "12,345.67"
+//    "#,###." => "12,345"
+//    "#,###" => "12345,678"
+//    "#\u202F###,##" => "12 345,67"
+//    "#.###,###### => 12.345,678900
+//    "" (aka default format) => 12,345.67
+//RenderFloat panics on invalid formats, use ParseNumberFormat to validate formats which are not constant
+//Author: https://github.com/gorhill, Source: https://gist.github.com/gorhill/5285193
+func RenderFloat(format string, n float64) string {
+	f, err := ParseNumberFormat(format)
+
+	if err != nil {
+		panic("RenderFloat(): " + err.Error())
+	}
+
+	return f.Format(n)
+}
+
+//RenderSignificant formats n according to the provided format using the given number of significant digits
+func RenderSignificant(format string, n float64, digits int) string {
+	f, err := ParseNumberFormat(format)
+
+	if err != nil {
+		panic("RenderSignificant(): " + err.Error())
 	}
 
-	return signStr + intStr + decimalStr + fracStr
+	return f.Significant(digits).Format(n)
 }
 
 //RenderInteger formats a given integer n according to the provided format
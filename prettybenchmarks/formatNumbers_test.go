@@ -13,6 +13,12 @@ var (
 		{12345.6789, "#,###", "12345,679"},
 		{12345.6789, "#\u202F###,##", "12 345,68"},
 		{12345.6789, "#.###,######", "12.345,678900"},
+		{12345.6789, "", "12,345.68"},
+		{-12345.6789, "#,###.##", "-12,345.68"},
+		{12345.6789, "+#,###.##", "+12,345.68"},
+		{-0.0001, "#,###.##", "0.00"},
+		{0.000000000001234, "#.############", "0.000000000001"},
+		{1e20, "#,###.", "100,000,000,000,000,000,000"},
 	}
 	testsInt = []struct {
 		input    int
@@ -44,3 +50,66 @@ func Test_RenderInteger(t *testing.T) {
 		}
 	}
 }
+
+func Test_ParseNumberFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format string
+		err    error
+	}{
+		{"#,###.##", nil},
+		{"+#,###.##", nil},
+		{"-#,###.##", ErrPositiveSign},
+		{"#,##.##", ErrThousandsSeparator},
+		{"#,###.###'##", ErrTooManyDirectives},
+	} {
+		if _, err := ParseNumberFormat(tt.format); err != tt.err {
+			t.Errorf("Parsing format %s: expected error %v, got %v", tt.format, tt.err, err)
+		}
+	}
+}
+
+func Test_RenderSignificant(t *testing.T) {
+	for _, tt := range []struct {
+		input    float64
+		digits   int
+		expected string
+	}{
+		{12345.6789, 3, "12,300"},
+		{109.805, 4, "109.8"},
+		{109.805, 3, "110"},
+		{999.7, 3, "1,000"},
+		{0.0012345, 3, "0.00123"},
+		{0.001, 3, "0.00100"},
+		{0, 3, "0.00"},
+		{-42.123, 2, "-42"},
+		{12345.6789, 0, "12,345.68"},
+	} {
+		actual := RenderSignificant("#,###.##", tt.input, tt.digits)
+		if tt.expected != actual {
+			t.Errorf("Rendering %f with %d significant digits: expected: %v, got: %v", tt.input, tt.digits, tt.expected, actual)
+		}
+	}
+}
+
+func Test_Trimmed(t *testing.T) {
+	for _, tt := range []struct {
+		format   string
+		input    float64
+		expected string
+	}{
+		{"#,###.##", 12.5, "12.5"},
+		{"#,###.##", 12, "12"},
+		{"#,###.##", 1234.567, "1,234.57"},
+		{"#.###,###", 0.25, "0,25"},
+	} {
+		f, err := ParseNumberFormat(tt.format)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := f.Trimmed().Format(tt.input); actual != tt.expected {
+			t.Errorf("Rendering %f trimmed with format %s: expected: %v, got: %v", tt.input, tt.format, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package prettybenchmarks
+
+import (
+	"math"
+
+	"github.com/apcera/termtables"
+)
+
+const geomeanName = "geomean"
+
+// geomean returns the geometric mean of the positive values, 0 if there are none. Like benchstat it
+// skips zeros, which would turn the mean into 0, and missing values
+func geomean(values []float64) float64 {
+	var (
+		sum float64
+		n   int
+	)
+
+	for _, v := range values {
+		if v > 0 {
+			sum += math.Log(v)
+			n++
+		}
+	}
+
+	if n == 0 {
+		return 0
+	}
+
+	return math.Exp(sum / float64(n))
+}
+
+// geomeanResult returns a result holding the geometric mean of every metric of rs
+func geomeanResult(rs []*result) *result {
+	g := &result{
+		Name:         geomeanName,
+		FnIterations: -1,
+		Procs:        1,
+		Speed:        geomean(values(rs, speed)),
+		Bps:          geomean(values(rs, bytesPerOp)),
+		Aps:          geomean(values(rs, allocsPerOp)),
+	}
+
+	for _, r := range rs {
+		for unit := range r.Metrics {
+			if _, ok := g.Metrics[unit]; ok {
+				continue
+			}
+
+			if g.Metrics == nil {
+				g.Metrics = make(map[string]float64)
+			}
+
+			var vs []float64
+
+			for _, s := range rs {
+				if v, ok := s.Metrics[unit]; ok {
+					vs = append(vs, v)
+				}
+			}
+
+			g.Metrics[unit] = geomean(vs)
+		}
+	}
+
+	return g
+}
+
+// addGeomeanRow adds a row holding the geometric mean of every metric column over all rows of bm,
+// if there is more than one row
+func addGeomeanRow(t *termtables.Table, bm *benchmark, cols []*column) {
+	rows := allResults(bm)
+
+	if len(rows) < 2 {
+		return
+	}
+
+	cells := make([]interface{}, 0, len(cols))
+
+	for _, c := range cols {
+		var cell string
+
+		switch {
+		case c.geomean != nil:
+			cell = c.geomean(bm, rows)
+		case c.name == "name":
+			cell = bold(geomeanName)
+		default:
+			cell = geomeanCell(c, bm, rows)
+		}
+
+		cells = append(cells, cell)
+	}
+
+	t.AddSeparator()
+	t.AddRow(cells...)
+}
+
+// geomeanCell renders the geometric mean of c's metric over rows, empty if c holds no metric or none of rows has it
+func geomeanCell(c *column, bm *benchmark, rows []*result) string {
+	if c.value == nil || c.render == nil {
+		return ""
+	}
+
+	v := geomean(values(rows, c.value))
+
+	if v == 0 {
+		return ""
+	}
+
+	// the row spans all groups, so with one unit per group it picks its own unit like a single cell
+	if unitMode == unitsGroup {
+		unitMode = unitsCell
+		defer func() { unitMode = unitsGroup }()
+	}
+
+	return c.render(bm, &result{Name: geomeanName, FnIterations: -1, Procs: 1}, v)
+}
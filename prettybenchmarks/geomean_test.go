@@ -0,0 +1,134 @@
+package prettybenchmarks
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_geomean(t *testing.T) {
+	for _, tt := range []struct {
+		values   []float64
+		expected float64
+	}{
+		{nil, 0},
+		{[]float64{0, 0}, 0},
+		{[]float64{2, 8}, 4},
+		{[]float64{2, 0, 8, -1}, 4},
+		{[]float64{1, 10, 100}, 10},
+	} {
+		if actual := geomean(tt.values); math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("Geomean of %v: expected %v, actual %v", tt.values, tt.expected, actual)
+		}
+	}
+}
+
+func Test_geomeanResult(t *testing.T) {
+	g := geomeanResult([]*result{
+		{Speed: 100, Bps: 0, Aps: 2, Metrics: map[string]float64{"MB/s": 10}},
+		{Speed: 400, Bps: 0, Aps: 8},
+	})
+
+	if math.Abs(g.Speed-200) > 1e-9 || g.Bps != 0 || g.Aps != 4 || math.Abs(g.Metrics["MB/s"]-10) > 1e-9 {
+		t.Errorf("Geomean result: expected 200 ns/op, 0 B/op, 4 allocs/op and 10 MB/s, actual %#v", g)
+	}
+}
+
+func Test_comparisonGeomean(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	cmp, err := newComparison(testInputs, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := selectColumns(cmp.rows, "name,time,allocs")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := allResults(cmp.rows)
+
+	var cells []string
+
+	for _, c := range cmp.columns(cols) {
+		if c.geomean != nil {
+			cells = append(cells, c.geomean(cmp.rows, rows))
+		}
+	}
+
+	if expected := []string{"0.742", "2.653", "-20.00%", "2", "2", "0.00%"}; !reflect.DeepEqual(cells, expected) {
+		t.Errorf("Comparing geomeans: expected cells %#v, actual %#v", expected, cells)
+	}
+
+	out := cmp.jsonResults()
+
+	if delta := out.Deltas["new"]["ns/op"]; math.Abs(delta+20) > 1e-9 {
+		t.Errorf("Comparing geomeans: expected ns/op delta -20, actual %v", delta)
+	}
+
+	if _, ok := out.Deltas["old"]; ok {
+		t.Errorf("Comparing geomeans: expected no deltas of the baseline")
+	}
+}
+
+func Test_geomeanCell(t *testing.T) {
+	defer setBytesPerOp("")
+
+	if err := setBytesPerOp("Encode=102.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := newBenchmark([][]byte{
+		[]byte("BenchmarkEncode-8 	 1000	 1000 ns/op\n"),
+		[]byte("BenchmarkDecode-8 	 1000	 1000 ns/op	 100.00 MB/s\n"),
+	})
+
+	if actual := geomeanCell(findColumn(availableColumns(bm), "MB/s"), bm, allResults(bm)); actual != "101.193" {
+		t.Errorf("Geomean of derived and reported throughput: expected %q, actual %q", "101.193", actual)
+	}
+
+	bm = newBenchmark([][]byte{
+		[]byte("Benchmark_Sort_10-8 	 1000	 100 ns/op\n"),
+		[]byte("Benchmark_Sort_1000-8 	 1000	 40000 ns/op\n"),
+	})
+
+	if actual := geomeanCell(findColumn(availableColumns(bm), "element"), bm, allResults(bm)); actual != "20.00 ns" {
+		t.Errorf("Geomean of time per element: expected %q, actual %q", "20.00 ns", actual)
+	}
+}
+
+func Test_geomeanCellGroupUnits(t *testing.T) {
+	defer func() {
+		unitMode = unitsGlobal
+		byteMode = bytesRaw
+	}()
+
+	unitMode = unitsGroup
+	byteMode = bytesIEC
+
+	bm := newBenchmark([][]byte{
+		[]byte("BenchmarkFast-8 	 1000	 3.20 ns/op	 64 B/op	 1 allocs/op\n"),
+		[]byte("BenchmarkSlow-8 	 1	 1840000000 ns/op	 1048576 B/op	 1 allocs/op\n"),
+	})
+
+	for _, tt := range []struct {
+		column   string
+		expected string
+	}{
+		{"time", "76.73 µs"},
+		{"bytes", "8.00 KiB/op"},
+	} {
+		if actual := geomeanCell(findColumn(availableColumns(bm), tt.column), bm, allResults(bm)); actual != tt.expected {
+			t.Errorf("Geomean of %s with one unit per group: expected %q, actual %q", tt.column, tt.expected, actual)
+		}
+	}
+
+	if unitMode != unitsGroup {
+		t.Errorf("Geomean with one unit per group: expected unit mode %q to be restored, actual %q", unitsGroup, unitMode)
+	}
+}
@@ -0,0 +1,149 @@
+package prettybenchmarks
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errNoGitRepository is returned if no .git directory is found in dir or any of its parents
+var errNoGitRepository = errors.New("not a git repository")
+
+// gitHead returns the commit hash and branch name (empty if detached) checked out in the repository
+// containing dir. It reads the .git directory directly instead of calling git
+func gitHead(dir string) (commit, branch string, err error) {
+	gitDir, commonDir, err := findGitDir(dir)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(gitDir, "HEAD"))
+
+	if err != nil {
+		return "", "", err
+	}
+
+	ref := strings.TrimSpace(string(head))
+
+	if !strings.HasPrefix(ref, "ref: ") {
+		// detached HEAD
+		return ref, "", nil
+	}
+
+	ref = strings.TrimPrefix(ref, "ref: ")
+	branch = strings.TrimPrefix(ref, "refs/heads/")
+
+	for _, d := range []string{gitDir, commonDir} {
+		if b, err := ioutil.ReadFile(filepath.Join(d, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(b)), branch, nil
+		}
+	}
+
+	commit, err = packedRef(commonDir, ref)
+
+	return commit, branch, err
+}
+
+// findGitDir walks up from dir to the first .git directory (or .git file of a worktree) and returns
+// the git directory and the common directory holding shared refs
+func findGitDir(dir string) (gitDir, commonDir string, err error) {
+	dir, err = filepath.Abs(dir)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, ".git")
+		stat, err := os.Stat(candidate)
+
+		if err == nil {
+			if stat.IsDir() {
+				return candidate, candidate, nil
+			}
+
+			return worktreeGitDir(dir, candidate)
+		}
+
+		parent := filepath.Dir(dir)
+
+		if parent == dir {
+			return "", "", errNoGitRepository
+		}
+
+		dir = parent
+	}
+}
+
+// worktreeGitDir resolves a .git file ("gitdir: <path>") as used by linked worktrees and submodules
+func worktreeGitDir(dir, file string) (gitDir, commonDir string, err error) {
+	b, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	content := strings.TrimSpace(string(b))
+
+	if !strings.HasPrefix(content, "gitdir: ") {
+		return "", "", errNoGitRepository
+	}
+
+	gitDir = strings.TrimPrefix(content, "gitdir: ")
+
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	commonDir = gitDir
+
+	if b, err := ioutil.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(b))
+
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	return gitDir, commonDir, nil
+}
+
+// packedRef looks up ref in the packed-refs file of the git directory
+func packedRef(gitDir, ref string) (string, error) {
+	f, err := os.Open(filepath.Join(gitDir, "packed-refs"))
+
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", errors.New("unknown ref " + ref)
+}
+
+// shortCommit abbreviates a commit hash the way git does by default
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
@@ -0,0 +1,57 @@
+package prettybenchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_gitHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"repo/.git/HEAD":                   "ref: refs/heads/main\n",
+		"repo/.git/refs/heads/main":        "1111111111111111111111111111111111111111\n",
+		"repo/.git/packed-refs":            "# pack-refs with: peeled fully-peeled sorted\n2222222222222222222222222222222222222222 refs/heads/packed\n",
+		"repo/.git/worktrees/wt/HEAD":      "ref: refs/heads/packed\n",
+		"repo/.git/worktrees/wt/commondir": "../..\n",
+		"wt/.git":                          "gitdir: " + filepath.Join(dir, "repo/.git/worktrees/wt") + "\n",
+		"detached/.git/HEAD":               "3333333333333333333333333333333333333333\n",
+		"repo/pkg/sub/.keep":               "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		dir    string
+		commit string
+		branch string
+	}{
+		{"repo/pkg/sub", "1111111111111111111111111111111111111111", "main"},
+		{"wt", "2222222222222222222222222222222222222222", "packed"},
+		{"detached", "3333333333333333333333333333333333333333", ""},
+	} {
+		commit, branch, err := gitHead(filepath.Join(dir, tt.dir))
+
+		if err != nil || commit != tt.commit || branch != tt.branch {
+			t.Errorf("Reading git HEAD in %s: expected %s (%s), actual %s (%s), error %v", tt.dir, tt.commit, tt.branch, commit, branch, err)
+		}
+	}
+}
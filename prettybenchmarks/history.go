@@ -0,0 +1,314 @@
+package prettybenchmarks
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const historyFile = "runs.jsonl"
+
+// historyRecord is one recorded run as stored in the history, one JSON object per line
+type historyRecord struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Commit    string            `json:"commit,omitempty"`
+	Branch    string            `json:"branch,omitempty"`
+	Config    map[string]string `json:"config,omitempty"`
+	Results   []*result         `json:"results"`
+}
+
+// label identifies the record in tables
+func (h *historyRecord) label() string {
+	label := h.Timestamp.Local().Format("2006-01-02 15:04:05")
+
+	if h.Commit != "" {
+		label += " " + shortCommit(h.Commit)
+	}
+
+	return label
+}
+
+// recordCommand implements 'pb record [files]': it parses the benchmarks and appends them,
+// annotated with time, git commit and the configuration printed by go test, to the history
+func recordCommand(args []string) error {
+	inputs, err := readInputs(args)
+
+	if err != nil {
+		return err
+	}
+
+	var l [][]byte
+
+	for _, in := range inputs {
+		l = append(l, in.lines...)
+	}
+
+	// in strict mode malformed results are not recorded at all
+	diagnostics := inputDiagnostics(inputs)
+	printDiagnostics(os.Stderr, diagnostics)
+
+	if err := strictError(diagnostics); err != nil {
+		return err
+	}
+
+	record := newHistoryRecord(l, time.Now())
+
+	if len(record.Results) == 0 {
+		return errors.New("no benchmarks found")
+	}
+
+	// outside of a git repository the commit is simply omitted
+	record.Commit, record.Branch, _ = gitHead(".")
+
+	if err := appendHistory(*historyFlag, record); err != nil {
+		return err
+	}
+
+	fmt.Printf("recorded %d benchmarks of %s to %s\n", len(record.Results), record.label(), filepath.Join(*historyFlag, historyFile))
+
+	return nil
+}
+
+// historyCommand implements 'pb history <benchmark>': it renders one row per recorded run of the benchmark
+func historyCommand(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: pb history <benchmark>")
+	}
+
+	records, err := readHistory(*historyFlag)
+
+	if err != nil {
+		return err
+	}
+
+	bm := newHistoryBenchmark(records, args[0])
+
+	if len(*bm.results) == 0 {
+		return fmt.Errorf("no recorded runs of %s in %s", args[0], *historyFlag)
+	}
+
+	cols, err := selectColumns(bm, *columnsFlag)
+
+	if err != nil {
+		return err
+	}
+
+	renderTable(bm, historyColumns(cols))
+
+	return nil
+}
+
+func newHistoryRecord(l [][]byte, now time.Time) *historyRecord {
+	record := &historyRecord{Timestamp: now.UTC()}
+	p := &lineParser{}
+
+	for _, line := range l {
+		r, err := p.parse(line)
+
+		if r != nil {
+			record.Results = append(record.Results, r)
+			continue
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if m := regExConfig.FindStringSubmatch(strings.TrimSpace(string(line))); m != nil && m[1] != "panic" {
+			if record.Config == nil {
+				record.Config = make(map[string]string)
+			}
+
+			record.Config[m[1]] = m[2]
+		}
+	}
+
+	return record
+}
+
+func appendHistory(dir string, record *historyRecord) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, historyFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(record)
+
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// readHistory returns all recorded runs, oldest first
+func readHistory(dir string) ([]*historyRecord, error) {
+	f, err := os.Open(filepath.Join(dir, historyFile))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var records []*historyRecord
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		record := &historyRecord{}
+
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", f.Name(), line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Stable(sortByTimestamp(records))
+
+	return records, nil
+}
+
+type sortByTimestamp []*historyRecord
+
+func (h sortByTimestamp) Len() int           { return len(h) }
+func (h sortByTimestamp) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h sortByTimestamp) Less(i, j int) bool { return h[i].Timestamp.Before(h[j].Timestamp) }
+
+// newHistoryBenchmark collects the results of the named benchmark from all records, the samples of each
+// record are reduced to their median and labelled with the record
+func newHistoryBenchmark(records []*historyRecord, name string) *benchmark {
+	name = normalizeName(name)
+	found := make(results)
+
+	for _, record := range records {
+		keys, samples := groupSamples(record.Results, name)
+
+		for _, k := range keys {
+			agg := aggregate(samples[k])
+			agg.Source = record.label()
+			found[agg.Name] = append(found[agg.Name], agg)
+		}
+	}
+
+	for _, r := range found {
+		sort.Stable(sortByFnIterations(r))
+	}
+
+	return &benchmark{
+		info:    newBenchmarkInfo(&found),
+		results: &found,
+	}
+}
+
+// groupSamples groups rs by benchmark, keeping the order in which benchmarks appear.
+// A non empty name only keeps results matching it, see matchesBenchmark
+func groupSamples(rs []*result, name string) ([]resultKey, map[resultKey][]*result) {
+	var keys []resultKey
+
+	samples := make(map[resultKey][]*result)
+
+	for _, r := range rs {
+		if name != "" && !matchesBenchmark(r, name) {
+			continue
+		}
+
+		if _, ok := samples[r.key()]; !ok {
+			keys = append(keys, r.key())
+		}
+
+		samples[r.key()] = append(samples[r.key()], r)
+	}
+
+	return keys, samples
+}
+
+// normalizeName strips the Benchmark prefix and procs suffix of a benchmark name as printed by go test
+func normalizeName(name string) string {
+	return regExByIterations.ReplaceAllString(regExByRuns.ReplaceAllString(name, ""), "")
+}
+
+// matchesBenchmark reports whether r belongs to the (normalized) benchmark name, which may include the iterations suffix
+func matchesBenchmark(r *result, name string) bool {
+	return r.Name == name || fmt.Sprintf("%s_%d", r.Name, r.FnIterations) == name
+}
+
+// historyColumns shows the recorded run in place of the source column and adds the change
+// of the time per operation compared to the previous run
+func historyColumns(cols []*column) []*column {
+	var expanded []*column
+
+	for _, c := range cols {
+		switch c.name {
+		case "source":
+			expanded = append(expanded, &column{
+				name:    c.name,
+				header:  staticHeader("Run"),
+				align:   alignLeft,
+				format:  c.format,
+				visible: c.visible,
+			})
+		case "time":
+			expanded = append(expanded, c, &column{
+				name:   "delta",
+				header: staticHeader("Δ"),
+				align:  alignRight,
+				format: func(bm *benchmark, r *result, first bool) string {
+					if prev := previousRun(bm, r); prev != nil {
+						return formatDelta(prev.Speed, r.Speed, lowerIsBetter)
+					}
+					return ""
+				},
+				visible: always,
+			})
+		default:
+			expanded = append(expanded, c)
+		}
+	}
+
+	return expanded
+}
+
+// previousRun returns the result recorded before r for the same benchmark
+func previousRun(bm *benchmark, r *result) *result {
+	var prev *result
+
+	for _, s := range (*bm.results)[r.Name] {
+		if s == r {
+			return prev
+		}
+
+		if s.key() == r.key() {
+			prev = s
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package prettybenchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testHistoryLines = [][]byte{
+	[]byte("goos: linux\n"),
+	[]byte("goarch: amd64\n"),
+	[]byte("pkg: github.com/foobar/baz\n"),
+	[]byte("Benchmark_Foo_10-8 	 100	 1000 ns/op\n"),
+	[]byte("Benchmark_Foo_10-8 	 100	 1200 ns/op\n"),
+	[]byte("Benchmark_Bar-8 	 100	 500 ns/op\n"),
+	[]byte("PASS\n"),
+	[]byte("ok  	github.com/foobar/baz	11.164s\n"),
+}
+
+func Test_newHistoryRecord(t *testing.T) {
+	record := newHistoryRecord(testHistoryLines, time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC))
+
+	expectedConfig := map[string]string{"goos": "linux", "goarch": "amd64", "pkg": "github.com/foobar/baz"}
+
+	if !reflect.DeepEqual(record.Config, expectedConfig) {
+		t.Errorf("Recording config: expected %v, actual %v", expectedConfig, record.Config)
+	}
+
+	if len(record.Results) != 3 {
+		t.Errorf("Recording results: expected 3, actual %d", len(record.Results))
+	}
+}
+
+func Test_history(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	second := newHistoryRecord([][]byte{[]byte("Benchmark_Foo_10-8 	 100	 990 ns/op\n")}, time.Date(2015, 11, 2, 12, 0, 0, 0, time.UTC))
+	second.Commit = "0123456789abcdef"
+	first := newHistoryRecord(testHistoryLines, time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC))
+
+	for _, record := range []*historyRecord{second, first} {
+		if err := appendHistory(dir, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := readHistory(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 || !records[0].Timestamp.Equal(first.Timestamp) || records[1].Commit != second.Commit {
+		t.Fatalf("Reading history: expected records sorted by time, actual %#v", records)
+	}
+
+	for _, name := range []string{"Foo", "BenchmarkFoo_10-8"} {
+		bm := newHistoryBenchmark(records, name)
+		rs := (*bm.results)["Foo"]
+
+		if len(rs) != 2 || rs[0].Speed != 1100 || rs[1].Speed != 990 {
+			t.Errorf("Collecting history of %s: expected medians 1100 and 990, actual %#v", name, rs)
+			continue
+		}
+
+		if prev := previousRun(bm, rs[1]); prev != rs[0] {
+			t.Errorf("Collecting history of %s: expected previous run %#v, actual %#v", name, rs[0], prev)
+		}
+	}
+}
+
+func Test_newHistoryRecordInterleaved(t *testing.T) {
+	record := newHistoryRecord([][]byte{
+		[]byte("goos: linux\n"),
+		[]byte("BenchmarkFoo\n"),
+		[]byte("BenchmarkFoo-8   \thello from the benchmark\n"),
+		[]byte("    foo_test.go:10: warming up\n"),
+		[]byte("     100\t      1000 ns/op\n"),
+		[]byte("PASS\n"),
+	}, time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC))
+
+	if len(record.Results) != 1 || record.Results[0].Name != "Foo" || record.Results[0].Speed != 1000 {
+		t.Errorf("Recording interleaved results: expected one result of Foo with 1000 ns/op, actual %v", record.Results)
+	}
+
+	if expected := map[string]string{"goos": "linux"}; !reflect.DeepEqual(record.Config, expected) {
+		t.Errorf("Recording config: expected %v, actual %v", expected, record.Config)
+	}
+}
@@ -0,0 +1,191 @@
+package prettybenchmarks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	inputsMerge    = "merge"
+	inputsSeparate = "separate"
+	inputsCompare  = "compare"
+
+	stdinArg   = "-"
+	stdinLabel = "stdin"
+)
+
+// inputMode defines how benchmarks of multiple inputs are rendered
+var inputMode = inputsMerge
+
+// input holds the lines read from one source (a file or stdin) and the label used to refer to it
+type input struct {
+	label string
+	lines [][]byte
+}
+
+func setInputMode(mode string) error {
+	switch mode {
+	case inputsMerge, inputsSeparate, inputsCompare:
+		inputMode = mode
+	case "":
+		inputMode = inputsMerge
+	default:
+		return fmt.Errorf("invalid mode %q, use %s, %s or %s", mode, inputsMerge, inputsSeparate, inputsCompare)
+	}
+
+	return nil
+}
+
+// readInputs reads all files matching the given paths or glob patterns, "-" reads stdin.
+// Without any arguments stdin is read. An argument in the form label=path labels its inputs
+func readInputs(args []string) ([]*input, error) {
+	if len(args) == 0 {
+		args = []string{stdinArg}
+	}
+
+	var (
+		inputs    []*input
+		stdinRead bool
+	)
+
+	for _, arg := range args {
+		label := ""
+
+		if i := strings.Index(arg, "="); i > 0 {
+			if _, err := os.Stat(arg); os.IsNotExist(err) {
+				label, arg = arg[:i], arg[i+1:]
+			}
+		}
+
+		paths := []string{arg}
+
+		if arg != stdinArg {
+			matches, err := filepath.Glob(arg)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(matches) > 0 {
+				paths = matches
+			}
+		}
+
+		for _, path := range paths {
+			// stdin can only be read once, a second read would yield an empty input
+			if path == stdinArg {
+				if stdinRead {
+					return nil, fmt.Errorf("%s (stdin) can only be given once", stdinArg)
+				}
+
+				stdinRead = true
+			}
+
+			in, err := readInput(path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if label != "" {
+				in.label = label
+
+				if len(paths) > 1 {
+					in.label = label + ":" + path
+				}
+			}
+
+			inputs = append(inputs, in)
+		}
+	}
+
+	return inputs, nil
+}
+
+func readInput(path string) (*input, error) {
+	if path == stdinArg {
+		l, err := readLines(os.Stdin)
+		return &input{stdinLabel, l}, err
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	l, err := readLines(f)
+
+	return &input{path, l}, err
+}
+
+func readLines(r io.Reader) ([][]byte, error) {
+	var l [][]byte
+
+	reader := bufio.NewReader(r)
+
+	for {
+		text, err := reader.ReadBytes('\n')
+
+		if len(text) > 0 {
+			l = append(l, text)
+		}
+
+		if err != nil {
+			if err != io.EOF {
+				return l, err
+			}
+			break
+		}
+	}
+
+	return l, nil
+}
+
+// newMergedBenchmark combines the results of all inputs into one benchmark,
+// each result remembers the label of its input
+func newMergedBenchmark(inputs []*input) *benchmark {
+	merged := make(results)
+
+	for _, in := range inputs {
+		for name, rs := range *newResults(in.lines) {
+			for _, r := range rs {
+				r.Source = in.label
+			}
+
+			merged[name] = append(merged[name], rs...)
+		}
+	}
+
+	for _, r := range merged {
+		sort.Stable(sortByFnIterations(r))
+	}
+
+	return &benchmark{
+		info:    newBenchmarkInfo(&merged),
+		results: &merged,
+	}
+}
+
+// hasMultipleSources reports whether the results of bm were read from more than one input
+func hasMultipleSources(bm *benchmark) bool {
+	source := ""
+
+	for _, rs := range *bm.results {
+		for _, r := range rs {
+			if source != "" && r.Source != source {
+				return true
+			}
+			source = r.Source
+		}
+	}
+
+	return false
+}
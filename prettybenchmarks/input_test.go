@@ -0,0 +1,80 @@
+package prettybenchmarks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_readLines(t *testing.T) {
+	actual, err := readLines(strings.NewReader("PASS\nBenchmarkFoo-8 10 5 ns/op"))
+	expected := [][]byte{[]byte("PASS\n"), []byte("BenchmarkFoo-8 10 5 ns/op")}
+
+	if err != nil || !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Reading lines: expected %q, actual %q (%v)", expected, actual, err)
+	}
+}
+
+func Test_readInputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.txt", "b.txt", "c.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("BenchmarkFoo-8 10 5 ns/op\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs, err := readInputs([]string{filepath.Join(dir, "*.txt"), filepath.Join(dir, "c.log")})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var labels []string
+
+	for _, in := range inputs {
+		labels = append(labels, filepath.Base(in.label))
+	}
+
+	if expected := []string{"a.txt", "b.txt", "c.log"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Reading inputs: expected %v, actual %v", expected, labels)
+	}
+
+	if _, err := readInputs([]string{filepath.Join(dir, "missing.txt")}); err == nil {
+		t.Errorf("Reading missing input: expected error")
+	}
+
+	if _, err := readInputs([]string{"old=" + stdinArg, filepath.Join(dir, "a.txt"), "new=" + stdinArg}); err == nil {
+		t.Errorf("Reading stdin twice: expected error")
+	}
+}
+
+func Test_newMergedBenchmark(t *testing.T) {
+	bm := newMergedBenchmark([]*input{
+		{"old.txt", [][]byte{[]byte("BenchmarkFoo-8 10 5 ns/op\n")}},
+		{"new.txt", [][]byte{[]byte("BenchmarkFoo-8 10 4 ns/op\n"), []byte("BenchmarkBar-8 10 4 ns/op\n")}},
+	})
+
+	var sources []string
+
+	for _, r := range (*bm.results)["Foo"] {
+		sources = append(sources, r.Source)
+	}
+
+	if expected := []string{"old.txt", "new.txt"}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Merging inputs: expected sources %v, actual %v", expected, sources)
+	}
+
+	if !hasMultipleSources(bm) {
+		t.Errorf("Merging inputs: expected multiple sources")
+	}
+}
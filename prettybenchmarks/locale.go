@@ -0,0 +1,115 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type numberLocale struct {
+	thousands string
+	decimal   string
+}
+
+const narrowNoBreakSpace = "\u202F"
+
+// locales maps languages (and regions deviating from their language) to their number separators
+var locales = map[string]numberLocale{
+	"c":     {",", "."},
+	"posix": {",", "."},
+	"en":    {",", "."},
+	"ja":    {",", "."},
+	"zh":    {",", "."},
+	"de":    {".", ","},
+	"de_ch": {"'", "."},
+	"da":    {".", ","},
+	"es":    {".", ","},
+	"it":    {".", ","},
+	"nl":    {".", ","},
+	"pt":    {".", ","},
+	"tr":    {".", ","},
+	"fr":    {narrowNoBreakSpace, ","},
+	"fr_ch": {narrowNoBreakSpace, "."},
+	"cs":    {narrowNoBreakSpace, ","},
+	"fi":    {narrowNoBreakSpace, ","},
+	"nb":    {narrowNoBreakSpace, ","},
+	"pl":    {narrowNoBreakSpace, ","},
+	"ru":    {narrowNoBreakSpace, ","},
+	"sv":    {narrowNoBreakSpace, ","},
+}
+
+// setLocale rewrites the number formats according to the given locale, which falls back to the
+// environment (LC_ALL, LC_NUMERIC, LANG) if empty. A precision > -1 sets the decimal places of time values
+func setLocale(name string, precision int) error {
+	l, ok := findLocale(name)
+
+	if !ok {
+		if name != "" {
+			return fmt.Errorf("unknown locale %q", name)
+		}
+
+		l, ok = findLocale(envLocale())
+
+		if !ok {
+			l = locales["en"]
+		}
+	}
+
+	if precision < -1 {
+		return fmt.Errorf("invalid precision %d", precision)
+	}
+
+	fmtInt = numberFormat(l, 0)
+	fmtFloat = numberFormat(l, 3)
+	fmtFloatNS = numberFormat(l, 0)
+	fmtFloatUnit = numberFormat(l, 2)
+	fmtTime = fmtFloat
+	fmtTimeUnit = fmtFloatUnit
+
+	if precision > -1 {
+		fmtFloatNS = numberFormat(l, precision)
+		fmtTime = fmtFloatNS
+		fmtTimeUnit = fmtFloatNS
+	}
+
+	return nil
+}
+
+// findLocale looks up names like "de", "de-CH" or "de_DE.UTF-8", first by language and region, then by language only
+func findLocale(name string) (numberLocale, bool) {
+	name = strings.ToLower(strings.Replace(name, "-", "_", -1))
+
+	if i := strings.IndexAny(name, ".@"); i > -1 {
+		name = name[:i]
+	}
+
+	if name == "" {
+		return numberLocale{}, false
+	}
+
+	if l, ok := locales[name]; ok {
+		return l, true
+	}
+
+	if i := strings.Index(name, "_"); i > -1 {
+		l, ok := locales[name[:i]]
+		return l, ok
+	}
+
+	return numberLocale{}, false
+}
+
+func envLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
+
+// numberFormat builds a RenderFloat format string for the locale with the given decimal places
+func numberFormat(l numberLocale, precision int) string {
+	return "#" + l.thousands + "###" + l.decimal + strings.Repeat("#", precision)
+}
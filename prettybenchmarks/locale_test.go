@@ -0,0 +1,45 @@
+package prettybenchmarks
+
+import (
+	"testing"
+)
+
+func Test_setLocale(t *testing.T) {
+	defer setLocale("en", -1)
+
+	// the environment of the caller must not leak into the cases without a locale
+	t.Setenv("LC_NUMERIC", "")
+	t.Setenv("LANG", "")
+
+	for _, tt := range []struct {
+		locale    string
+		precision int
+		env       string
+		expected  string
+		err       bool
+	}{
+		{"", -1, "", "1,234,567.891", false},
+		{"de", -1, "", "1.234.567,891", false},
+		{"de_CH.UTF-8", -1, "", "1'234'567.891", false},
+		{"fr-FR", 1, "", "1\u202F234\u202F567,9", false},
+		{"", -1, "de_DE.UTF-8", "1.234.567,891", false},
+		{"", -1, "xx_XX", "1,234,567.891", false},
+		{"xx", -1, "", "", true},
+		{"en", 10, "", "1,234,567.8910000001", false},
+		{"en", -2, "", "", true},
+	} {
+		t.Setenv("LC_ALL", tt.env)
+		err := setLocale(tt.locale, tt.precision)
+
+		if (err != nil) != tt.err {
+			t.Errorf("Setting locale %q: expected error %v, got %v", tt.locale, tt.err, err)
+			continue
+		}
+
+		if !tt.err {
+			if actual := RenderFloat(fmtTime, 1234567.891); actual != tt.expected {
+				t.Errorf("Formatting with locale %q (env %q): expected %v, actual %v", tt.locale, tt.env, tt.expected, actual)
+			}
+		}
+	}
+}
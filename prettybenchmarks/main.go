@@ -18,11 +18,10 @@
 package prettybenchmarks
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
-	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -33,21 +32,29 @@ import (
 	"github.com/apcera/termtables"
 )
 
-const (
-	fmtInt     = "#,###."
-	fmtFloat   = "#,###.###"
-	fmtFloatNS = "#,###."
+// number formats, see RenderFloat. They are rewritten by setLocale
+var (
+	fmtInt       = "#,###."
+	fmtFloat     = "#,###.###"
+	fmtFloatNS   = "#,###."
+	fmtFloatUnit = "#,###.##"
+	fmtTime      = fmtFloat
+	fmtTimeUnit  = fmtFloatUnit
 )
 
 type (
 	benchmark struct {
 		info    *benchmarkInfo
 		results *results
+		// samples holds the results aggregated into results, nil if they are not aggregated
+		samples *results
 	}
 	benchmarkInfo struct {
-		hasFnIterations bool
-		benchmemUsed    bool
-		suggestedTiming string
+		hasFnIterations  bool
+		benchmemUsed     bool
+		suggestedTiming  string
+		hasMultipleProcs bool
+		metrics          []string
 	}
 	results map[string][]*result
 	result  struct {
@@ -55,22 +62,44 @@ type (
 		FnIterations int
 		Runs         int
 		Speed        float64
-		Bps          int
-		Aps          int
+		Bps          float64
+		Aps          float64
+		Procs        int
+		Metrics      map[string]float64   `json:",omitempty"`
+		Source       string               `json:",omitempty"`
+		Discarded    int                  `json:",omitempty"`
+		Intervals    map[string]*interval `json:",omitempty"`
 	}
 )
 
+// resultKey identifies a benchmark independent of its sample
+type resultKey struct {
+	name         string
+	fnIterations int
+	procs        int
+}
+
+func (r *result) key() resultKey {
+	return resultKey{r.Name, r.FnIterations, r.Procs}
+}
+
 type sortByFnIterations []*result
 
-func (b sortByFnIterations) Len() int           { return len(b) }
-func (b sortByFnIterations) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
-func (b sortByFnIterations) Less(i, j int) bool { return b[i].FnIterations < b[j].FnIterations }
+func (b sortByFnIterations) Len() int      { return len(b) }
+func (b sortByFnIterations) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b sortByFnIterations) Less(i, j int) bool {
+	if b[i].FnIterations == b[j].FnIterations {
+		return b[i].Procs < b[j].Procs
+	}
+	return b[i].FnIterations < b[j].FnIterations
+}
 
 var (
 	regExByWhitespace = regexp.MustCompile(`\s+`)
 	regExByRuns       = regexp.MustCompile(`-\d+$`)
 	regExByIterations = regexp.MustCompile(`(?i:)(^Benchmark_?)`)
 	regExIsBenchmark  = regExByIterations
+	regExConfig       = regexp.MustCompile(`^([a-z][^\s:]*):\s+(.*)$`)
 	linePassed        = "PASS"
 	lineSkipped       = "SKIP"
 	lineFail          = "FAIL"
@@ -82,49 +111,181 @@ var (
 	table           *termtables.Table
 	bench           *benchmark
 	timing          string
+	columnsFlag     = flag.String("columns", "", "comma separated list of columns to show, in that order (e.g. name,time,allocs)")
+	colorFlag       = flag.String("color", colorAuto, "when to use colors: always, never or auto")
+	bytesFlag       = flag.String("bytes", bytesRaw, "how memory values are shown: raw, iec (KiB, MiB, ...) or si (kB, MB, ...)")
+	localeFlag      = flag.String("locale", "", "locale used for number formatting (e.g. en, de, fr), defaults to LC_ALL, LC_NUMERIC or LANG")
+	precisionFlag   = flag.Int("precision", -1, "decimal places of time values (default 0 for ns, 3 otherwise and 2 with --units=group|cell)")
+	sigFigsFlag     = flag.String("sigfigs", "", "render metrics with this many significant digits, or auto to derive them from the variance of -count samples")
+	modeFlag        = flag.String("mode", inputsMerge, "how multiple input files are shown: merge (one table), separate (one table per file) or compare (one column per file)")
+	baselineFlag    = flag.String("baseline", "", "label or 1-based index of the input deltas are computed against in compare mode, defaults to the first input")
+	formatFlag      = flag.String("format", formatTable, "output format: table or json")
+	historyFlag     = flag.String("history", filepath.Join(".pb", "history"), "directory of the benchmark history written by 'pb record'")
+	unitsFlag       = flag.String("units", unitsGlobal, "how units are chosen: global (one unit for all benchmarks), group (per benchmark) or cell (per value)")
+	roundsFlag      = flag.Int("rounds", 10, "number of rounds 'pb ab' runs both test binaries")
+	ciFlag          = flag.Float64("ci", 0, "render the median of each metric with its bootstrap confidence interval at this level in percent (e.g. 95)")
+	seedFlag        = flag.Int64("seed", 0, "seed of the bootstrap resampling for reproducible intervals, 0 picks a random one")
+	throughputFlag  = flag.Bool("throughput", false, "add the operations per second derived from ns/op")
+	bytesPerOpFlag  = flag.String("bytes-per-op", "", "comma separated regex=bytes rules deriving the throughput of benchmarks not calling b.SetBytes (e.g. Encode_1024=1024)")
+	strictFlag      = flag.Bool("strict", false, "exit with status 1 if any benchmark line holds malformed values, which are reported as warnings")
+	outliersFlag    = flag.String("outliers", "", "aggregate the samples of each benchmark to their mean after dropping outliers: none, iqr (beyond the Tukey fences) or trim:X (X% at either end), or to their median: median")
 )
 
-func init() {
-	setTiming()
-}
-
 // Main is the entry point to parse benchmarks
 // not intended for use in libraries, but has to be exported to ensure the tool can be called via 'pb'
 func Main() {
-	reader := bufio.NewReader(os.Stdin)
-	quit := make(chan bool)
-
-	go loading(quit)
+	flag.Parse()
+	args := setTiming(flag.Args())
 
-	for {
-		text, err := reader.ReadBytes('\n')
+	if err := configure(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
-		if err != nil {
-			if err != io.EOF {
-				panic(err)
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			if err := cmd(args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitStatus(err))
 			}
-			break
+			return
 		}
+	}
 
-		lines = append(lines, text)
+	quit := make(chan bool)
+
+	if spinnerEnabled {
+		go loading(quit)
 	}
 
+	inputs, err := readInputs(args)
+
 	close(quit)
 
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for _, in := range inputs {
+		lines = append(lines, in.lines...)
+	}
+
 	if len(lines) == 0 {
 		return
 	}
 
-	bench = newBenchmark(lines)
+	if spinnerEnabled {
+		fmt.Print("\r \n")
+	}
+
+	diagnostics := inputDiagnostics(inputs)
+	printDiagnostics(os.Stderr, diagnostics)
+
+	if outputFormat == formatJSON {
+		if err := printResultsJSON(inputs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		exitOnDiagnostics(diagnostics)
+		return
+	}
 
+	switch inputMode {
+	case inputsCompare:
+		cmp, err := newComparison(inputs, *baselineFlag)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		bench = cmp.merged
+		printComparison(cmp)
+	case inputsSeparate:
+		for _, in := range inputs {
+			if len(in.lines) == 0 {
+				continue
+			}
+
+			fmt.Println(bold(in.label))
+			bench = aggregateBenchmark(newBenchmark(in.lines))
+			printTable(bench)
+		}
+	default:
+		bench = aggregateBenchmark(newMergedBenchmark(inputs))
+		printTable(bench)
+	}
+
+	fmt.Println(footer())
+	exitOnDiagnostics(diagnostics)
+}
+
+// configure applies all flags which need validation
+func configure() error {
+	if err := setUnitMode(*unitsFlag); err != nil {
+		return err
+	}
+
+	if err := setByteMode(*bytesFlag); err != nil {
+		return err
+	}
+
+	if err := setLocale(*localeFlag, *precisionFlag); err != nil {
+		return err
+	}
+
+	if err := setSigFigs(*sigFigsFlag); err != nil {
+		return err
+	}
+
+	if err := setInputMode(*modeFlag); err != nil {
+		return err
+	}
+
+	if err := setOutlierMode(*outliersFlag); err != nil {
+		return err
+	}
+
+	if err := setBytesPerOp(*bytesPerOpFlag); err != nil {
+		return err
+	}
+
+	throughputMode = *throughputFlag
+	strictMode = *strictFlag
+
+	if err := setCI(*ciFlag, *seedFlag); err != nil {
+		return err
+	}
+
+	if err := setOutputFormat(*formatFlag); err != nil {
+		return err
+	}
+
+	return setColor(*colorFlag)
+}
+
+func printTable(bm *benchmark) {
+	cols, err := selectColumns(bm, *columnsFlag)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	noteNoise(bm)
+	renderTable(bm, cols)
+	fmt.Print(complexityReport(bm))
+}
+
+func renderTable(bm *benchmark, cols []*column) {
 	table = termtables.CreateTable()
 	table.Style.Alignment = termtables.AlignRight
-	addTableHeader(table)
-	addTableBody(table)
+	addTableHeader(table, bm, cols)
+	addTableBody(table, bm, cols)
+	addGeomeanRow(table, bm, cols)
 
-	fmt.Print("\r \n")
 	fmt.Println(table.Render())
-	fmt.Println(footer())
 }
 
 func newBenchmark(l [][]byte) *benchmark {
@@ -138,15 +299,20 @@ func newBenchmark(l [][]byte) *benchmark {
 
 func newResults(l [][]byte) *results {
 	benchMap := make(results)
+	p := &lineParser{}
 
 	for _, l := range l {
-		bl, err := newResult(l)
+		bl, err := p.parse(l)
 
 		if err != nil {
 			unparsableLines = append(unparsableLines, err.Error())
 			continue
 		}
 
+		if bl == nil {
+			continue
+		}
+
 		if _, ok := benchMap[bl.Name]; !ok {
 			benchMap[bl.Name] = make([]*result, 0)
 		}
@@ -154,29 +320,54 @@ func newResults(l [][]byte) *results {
 		benchMap[bl.Name] = append(benchMap[bl.Name], bl)
 	}
 
+	addBenchmarkLogs(p.logs)
+	benchmarkPanics = append(benchmarkPanics, p.panics...)
+
 	for _, r := range benchMap {
-		sort.Sort(sortByFnIterations(r))
+		sort.Stable(sortByFnIterations(r))
 	}
 
 	return &benchMap
 }
 
 func newResult(b []byte) (*result, error) {
+	r, _, err := parseResult(b)
+
+	return r, err
+}
+
+// parseResult parses a benchmark line like newResult and additionally returns a diagnostic for every
+// malformed value, located by its byte column in b
+func parseResult(b []byte) (*result, []*diagnostic, error) {
 	var (
-		name   string
-		fnIter int
-		bps    int
-		aps    int
-		err    error
-		iter   int
-		speed  float64
+		name    string
+		fnIter  int
+		bps     float64
+		aps     float64
+		err     error
+		iter    int
+		speed   float64
+		procs   int
+		metrics map[string]float64
+		diags   []*diagnostic
 	)
 
 	s := string(b)
 	parts := regExByWhitespace.Split(s, -1)
 
 	if len(parts) < 4 || !regExIsBenchmark.MatchString(parts[0]) {
-		return nil, fmt.Errorf("%s", s)
+		return nil, nil, fmt.Errorf("%s", s)
+	}
+
+	columns := fieldColumns(s, parts)
+	invalid := func(i int, what string) {
+		diags = append(diags, &diagnostic{column: columns[i], message: fmt.Sprintf("invalid %s %q", what, parts[i])})
+	}
+
+	procs = 1
+
+	if suffix := regExByRuns.FindString(parts[0]); suffix != "" {
+		procs, _ = strconv.Atoi(suffix[1:])
 	}
 
 	nameRuns := regExByRuns.ReplaceAllString(parts[0], "")
@@ -195,29 +386,55 @@ func newResult(b []byte) (*result, error) {
 
 	if err != nil {
 		iter = -1
+		invalid(1, "runs")
 	}
 
 	speed, err = strconv.ParseFloat(parts[2], 64)
 
 	if err != nil {
 		speed = -1
+		invalid(2, "ns/op")
 	}
 
-	if len(parts) > 5 {
-		bps, err = strconv.Atoi(parts[4])
+	// without benchmem
+	bps = -1
+	aps = -1
 
-		if err != nil {
-			bps = -1
-		}
-		aps, err = strconv.Atoi(parts[6])
+	// every further measurement is a value followed by its unit
+	for i := 4; i+1 < len(parts); i += 2 {
+		switch parts[i+1] {
+		case "B/op":
+			bps, err = strconv.ParseFloat(parts[i], 64)
 
-		if err != nil {
-			aps = -1
+			if err != nil {
+				bps = -1
+				invalid(i, "B/op")
+			}
+		case "allocs/op":
+			aps, err = strconv.ParseFloat(parts[i], 64)
+
+			if err != nil {
+				aps = -1
+				invalid(i, "allocs/op")
+			}
+		default:
+			if parts[i+1] == "" {
+				continue
+			}
+
+			v, err := strconv.ParseFloat(parts[i], 64)
+
+			if err != nil {
+				invalid(i, parts[i+1])
+				continue
+			}
+
+			if metrics == nil {
+				metrics = make(map[string]float64)
+			}
+
+			metrics[parts[i+1]] = v
 		}
-	} else {
-		// without benchmem
-		bps = -1
-		aps = -1
 	}
 
 	return &result{
@@ -227,20 +444,25 @@ func newResult(b []byte) (*result, error) {
 		Speed:        speed,
 		Bps:          bps,
 		Aps:          aps,
-	}, nil
+		Procs:        procs,
+		Metrics:      metrics,
+	}, diags, nil
 }
 
 func newBenchmarkInfo(r *results) *benchmarkInfo {
 	var (
-		hasFnIter    bool
-		benchmemUsed bool
-		wg           sync.WaitGroup
+		suggestedTiming string
+		hasFnIter       bool
+		benchmemUsed    bool
+		multipleProcs   bool
+		metrics         []string
+		wg              sync.WaitGroup
 	)
 
-	wg.Add(3)
+	wg.Add(5)
 
 	go func(r *results) {
-		timing = getSuggestedTiming(r)
+		suggestedTiming = getSuggestedTiming(r)
 		wg.Done()
 	}(r)
 
@@ -254,20 +476,19 @@ func newBenchmarkInfo(r *results) *benchmarkInfo {
 		wg.Done()
 	}(r)
 
-	wg.Wait()
+	go func(r *results) {
+		multipleProcs = hasMultipleProcs(r)
+		wg.Done()
+	}(r)
 
-	switch timing {
-	case "ns":
-		// ns is default, dont't do anything
-	case "µs":
-		updateSpeedVals(r, float64(1e3))
-	case "ms":
-		updateSpeedVals(r, float64(1e6))
-	case "s":
-		updateSpeedVals(r, float64(1e9))
-	}
+	go func(r *results) {
+		metrics = customMetrics(r)
+		wg.Done()
+	}(r)
+
+	wg.Wait()
 
-	return &benchmarkInfo{hasFnIter, benchmemUsed, timing}
+	return &benchmarkInfo{hasFnIter, benchmemUsed, suggestedTiming, multipleProcs, metrics}
 }
 
 func getSuggestedTiming(r *results) string {
@@ -285,16 +506,7 @@ func getSuggestedTiming(r *results) string {
 	}
 
 	if timing == "" {
-		switch {
-		case slowest <= 1e3:
-			suggestedTiming = "ns"
-		case slowest > 1e3 && slowest <= 1e6:
-			suggestedTiming = "µs"
-		case slowest > 1e6 && slowest <= 1e9:
-			suggestedTiming = "ms"
-		case slowest > 1e9:
-			suggestedTiming = "s"
-		}
+		suggestedTiming = suitableTiming(slowest)
 	} else {
 		suggestedTiming = timing
 	}
@@ -330,146 +542,50 @@ func hasFnIterations(r *results) bool {
 	return hasFnIterations
 }
 
-func updateSpeedVals(r *results, f float64) {
+func hasMultipleProcs(r *results) bool {
+	procs := -1
+
 	for _, bl := range *r {
 		for _, l := range bl {
-			l.Speed = l.Speed / f
-		}
-	}
-}
-
-func footer() string {
-	var footer []byte
-
-	footer = append(footer, []byte{10}...)
-	footer = append(footer, []byte((bold("Summary:"))+"\n")...)
-	footer = append(footer, []byte((bold("+------+"))+"\n")...)
-
-	for _, line := range unparsableLines {
-		tmp := strings.TrimSpace(line)
-		switch {
-		case tmp == linePassed:
-			footer = append(footer, []byte(green(bold(tmp))+"\n")...)
-		case tmp == lineSkipped:
-			footer = append(footer, []byte(gray(bold(tmp))+"\n")...)
-		case tmp == lineFail:
-			footer = append(footer, []byte(red(bold(tmp))+"\n")...)
-		default:
-			footer = append(footer, []byte(tmp+"\n")...)
+			if procs > -1 && l.Procs != procs {
+				return true
+			}
+			procs = l.Procs
 		}
 	}
 
-	return string(footer)
+	return false
 }
 
-func addTableHeader(t *termtables.Table) {
-	var lenLongestName int
+func customMetrics(r *results) []string {
+	var metrics []string
 
-	for name := range *bench.results {
-		if tmpLen := len(name); tmpLen > lenLongestName {
-			lenLongestName = tmpLen
+	for _, bl := range *r {
+		for _, l := range bl {
+			for unit := range l.Metrics {
+				if !StringsContains(metrics, unit) {
+					metrics = append(metrics, unit)
+				}
+			}
 		}
 	}
 
-	// add padding to first col since alignment in header columns does not work
-	// padding of longest name + len("name") + 1 padding right
-	nameCol := make([]byte, 0, lenLongestName+4+1)
-	nameCol = append(nameCol, []byte("Name")...)
+	sort.Strings(metrics)
 
-	for i := 0; i < lenLongestName; i++ {
-		nameCol = append(nameCol, byte(32))
-	}
-
-	if bench.info.benchmemUsed {
-		if bench.info.hasFnIterations {
-			t.AddHeaders(bold(string(nameCol)), bold("Iterations"), bold("Runs"), bold(bench.info.suggestedTiming+"/op"), bold("B/op"), bold("allocations/op"))
-		} else {
-			t.AddHeaders(bold(string(nameCol)), bold("Runs"), bold(bench.info.suggestedTiming+"/op"), bold("B/op"), bold("allocations/op"))
-		}
-	} else {
-		if bench.info.hasFnIterations {
-			t.AddHeaders(bold(string(nameCol)), bold("Iterations"), bold("Runs"), bold(bench.info.suggestedTiming+"/op"))
-		} else {
-			t.AddHeaders(bold(string(nameCol)), bold("Runs"), bold(bench.info.suggestedTiming+"/op"))
-		}
-	}
+	return metrics
 }
 
-func addTableBody(t *termtables.Table) {
-	floatFmt := fmtFloat
-
-	if bench.info.suggestedTiming == "ns" {
-		floatFmt = fmtFloatNS
-	}
-
-	i := len(*bench.results)
-	sorted := make([]string, 0, i)
-
-	for name := range *bench.results {
-		sorted = append(sorted, name)
-	}
-
-	sort.Sort(sort.StringSlice(sorted))
-
-	for _, benchName := range sorted {
-		results := (*bench.results)[benchName]
-
-		for j, b := range results {
-			var name string
-
-			if j == 0 {
-				name = bold(b.Name)
-			}
-
-			if bench.info.benchmemUsed {
-				if bench.info.hasFnIterations {
-					fnIterations := strconv.Itoa(b.FnIterations)
-
-					if fnIterations == "-1" {
-						fnIterations = ""
-					} else {
-						i, err := strconv.Atoi(fnIterations)
-						if err != nil {
-							fnIterations = ""
-						} else {
-							fnIterations = RenderInteger(fmtInt, i)
-						}
-					}
-
-					t.AddRow(name, fnIterations, RenderInteger(fmtInt, b.Runs), RenderFloat(floatFmt, b.Speed), RenderInteger(fmtInt, b.Bps), RenderInteger(fmtInt, b.Aps))
-				} else {
-					t.AddRow(name, RenderInteger(fmtInt, b.Runs), RenderFloat(floatFmt, b.Speed), RenderInteger(fmtInt, b.Bps), RenderInteger(fmtInt, b.Aps))
-				}
-			} else {
-				if bench.info.hasFnIterations {
-					fnIterations := strconv.Itoa(b.FnIterations)
-
-					if fnIterations == "-1" {
-						fnIterations = ""
-					} else {
-						i, err := strconv.Atoi(fnIterations)
-						if err != nil {
-							fnIterations = ""
-						} else {
-							fnIterations = RenderInteger(fmtInt, i)
-						}
-					}
-
-					t.AddRow(name, fnIterations, RenderInteger(fmtInt, b.Runs), RenderFloat(floatFmt, b.Speed))
-				} else {
-					t.AddRow(name, RenderInteger(fmtInt, b.Runs), RenderFloat(floatFmt, b.Speed))
-				}
-			}
-		}
+func footer() string {
+	var footer []byte
 
-		i--
+	footer = append(footer, []byte{10}...)
+	footer = append(footer, []byte((bold("Summary:"))+"\n")...)
+	footer = append(footer, []byte((bold("+------+"))+"\n")...)
 
-		if i > 0 {
-			t.AddSeparator()
-		}
-	}
+	footer = append(footer, []byte(newSummary(unparsableLines).String())...)
+	footer = append(footer, []byte(noiseFooter())...)
 
-	t.SetAlign(termtables.AlignLeft, 1)
+	return string(footer)
 }
 
 //StringsContains checks if a string slice contains search element
@@ -483,10 +599,8 @@ func StringsContains(elements []string, needle string) bool {
 	return false
 }
 
-func setTiming() {
-	flag.Parse()
-	args := flag.Args()
-
+// setTiming consumes the optional time interval argument and returns the remaining arguments
+func setTiming(args []string) []string {
 	if len(args) > 0 {
 		if lowerArg := strings.ToLower(args[0]); StringsContains([]string{"ns", "us", "µs", "ms", "s"}, lowerArg) {
 			if lowerArg == "us" {
@@ -494,8 +608,12 @@ func setTiming() {
 			}
 
 			timing = lowerArg
+
+			return args[1:]
 		}
 	}
+
+	return args
 }
 
 func loading(q chan bool) {
@@ -514,23 +632,27 @@ func loading(q chan bool) {
 			}
 
 		case <-q:
-			break
+			return
 		}
 	}
 }
 
 func bold(s string) string {
-	return fmt.Sprintf("\033[1m%s\033[0m", s)
+	return colorize("1", s)
 }
 
 func green(s string) string {
-	return fmt.Sprintf("\033[32m%s\033[0m", s)
+	return colorize("32", s)
 }
 
 func red(s string) string {
-	return fmt.Sprintf("\033[31m%s\033[0m", s)
+	return colorize("31", s)
+}
+
+func yellow(s string) string {
+	return colorize("33", s)
 }
 
 func gray(s string) string {
-	return fmt.Sprintf("\033[90m%s\033[0m", s)
+	return colorize("90", s)
 }
@@ -29,27 +29,27 @@ var tests = []struct {
 		},
 		&results{
 			"NewSmallReq": []*result{
-				{"NewSmallReq", -1, 100000, float64(21618), 2739, 45},
+				{"NewSmallReq", -1, 100000, float64(21618), 2739, 45, 8, nil, "", 0, nil},
 			},
 			"UnmarshalSmallReq": []*result{
-				{"UnmarshalSmallReq", -1, 100000, float64(22231), 3570, 100},
+				{"UnmarshalSmallReq", -1, 100000, float64(22231), 3570, 100, 8, nil, "", 0, nil},
 			},
 			"NewLargeReq": []*result{
-				{"NewLargeReq", -1, 10000, float64(122245), 29823, 54},
+				{"NewLargeReq", -1, 10000, float64(122245), 29823, 54, 8, nil, "", 0, nil},
 			},
 			"NewSmallReqProto": []*result{
-				{"NewSmallReqProto", -1, 100000, float64(15594), 2691, 44},
+				{"NewSmallReqProto", -1, 100000, float64(15594), 2691, 44, 8, nil, "", 0, nil},
 			},
 			"NewLargeReqProto": []*result{
-				{"NewLargeReqProto", -1, 10000, float64(170835), 26706, 53},
+				{"NewLargeReqProto", -1, 10000, float64(170835), 26706, 53, 8, nil, "", 0, nil},
 			},
 			"UnmarshalLargeReq": []*result{
-				{"UnmarshalLargeReq", 10, 5000, float64(342400), 60385, 1680},
-				{"UnmarshalLargeReq", 100, 5000, float64(342400), 60385, 1680},
-				{"UnmarshalLargeReq", 1000, 5000, float64(342400), 60385, 1680},
+				{"UnmarshalLargeReq", 10, 5000, float64(342400), 60385, 1680, 8, nil, "", 0, nil},
+				{"UnmarshalLargeReq", 100, 5000, float64(342400), 60385, 1680, 8, nil, "", 0, nil},
+				{"UnmarshalLargeReq", 1000, 5000, float64(342400), 60385, 1680, 8, nil, "", 0, nil},
 			},
 		},
-		&benchmarkInfo{true, true, "µs"},
+		&benchmarkInfo{true, true, "µs", false, nil},
 		[]string{
 			"PASS\n",
 			"ok  	github.com/foobar/baz	11.164s\n",
@@ -72,13 +72,13 @@ var tests = []struct {
 		},
 		&results{
 			"NewSmallReq": []*result{
-				{"NewSmallReq", -1, 100000, float64(21), 2739, 45},
+				{"NewSmallReq", -1, 100000, float64(21), 2739, 45, 8, nil, "", 0, nil},
 			},
 			"NewLargeReq": []*result{
-				{"NewLargeReq", -1, 10000, float64(122), 29823, 54},
+				{"NewLargeReq", -1, 10000, float64(122), 29823, 54, 8, nil, "", 0, nil},
 			},
 		},
-		&benchmarkInfo{false, true, "µs"},
+		&benchmarkInfo{false, true, "ns", false, nil},
 		[]string{
 			"FOO\n",
 			"\n",
@@ -88,7 +88,7 @@ var tests = []struct {
 			"fail  	github.com/foobar/baz	11.164s\n",
 			"??  	github.com/foobar/baz	11.164s\n",
 		},
-		"µs",
+		"ns",
 		true,
 		false,
 	},
@@ -108,27 +108,27 @@ var tests = []struct {
 		},
 		&results{
 			"NewSmallReq": []*result{
-				{"NewSmallReq", -1, 100000, float64(21618), -1, -1},
+				{"NewSmallReq", -1, 100000, float64(21618), -1, -1, 8, nil, "", 0, nil},
 			},
 			"UnmarshalSmallReq": []*result{
-				{"UnmarshalSmallReq", -1, 100000, float64(22231), -1, -1},
+				{"UnmarshalSmallReq", -1, 100000, float64(22231), -1, -1, 8, nil, "", 0, nil},
 			},
 			"NewLargeReq": []*result{
-				{"NewLargeReq", -1, 10000, float64(122245), -1, -1},
+				{"NewLargeReq", -1, 10000, float64(122245), -1, -1, 8, nil, "", 0, nil},
 			},
 			"NewSmallReqProto": []*result{
-				{"NewSmallReqProto", -1, 100000, float64(15594), -1, -1},
+				{"NewSmallReqProto", -1, 100000, float64(15594), -1, -1, 8, nil, "", 0, nil},
 			},
 			"NewLargeReqProto": []*result{
-				{"NewLargeReqProto", -1, 10000, float64(170835), -1, -1},
+				{"NewLargeReqProto", -1, 10000, float64(170835), -1, -1, 8, nil, "", 0, nil},
 			},
 			"UnmarshalLargeReq": []*result{
-				{"UnmarshalLargeReq", 10, 5000, float64(342400), -1, -1},
-				{"UnmarshalLargeReq", 100, 5000, float64(342400), -1, -1},
-				{"UnmarshalLargeReq", 1000, 5000, float64(342400), -1, -1},
+				{"UnmarshalLargeReq", 10, 5000, float64(342400), -1, -1, 8, nil, "", 0, nil},
+				{"UnmarshalLargeReq", 100, 5000, float64(342400), -1, -1, 8, nil, "", 0, nil},
+				{"UnmarshalLargeReq", 1000, 5000, float64(342400), -1, -1, 8, nil, "", 0, nil},
 			},
 		},
-		&benchmarkInfo{true, false, "µs"},
+		&benchmarkInfo{true, false, "µs", false, nil},
 		[]string{
 			"PASS\n",
 			"ok  	github.com/foobar/baz	22222.164s\n",
@@ -151,13 +151,13 @@ var tests = []struct {
 		},
 		&results{
 			"NewSmallReq": []*result{
-				{"NewSmallReq", -1, 100000, float64(216180000), -1, -1},
+				{"NewSmallReq", -1, 100000, float64(216180000), -1, -1, 8, nil, "", 0, nil},
 			},
 			"NewLargeReq": []*result{
-				{"NewLargeReq", -1, 10000, float64(1222450320), -1, -1},
+				{"NewLargeReq", -1, 10000, float64(1222450320), -1, -1, 8, nil, "", 0, nil},
 			},
 		},
-		&benchmarkInfo{false, false, "µs"},
+		&benchmarkInfo{false, false, "s", false, nil},
 		[]string{
 			"FOO\n",
 			"\n",
@@ -167,7 +167,7 @@ var tests = []struct {
 			"fail  	github.com/foobar/baz	11.164s\n",
 			"?foo?  	github.com/foobar/baz	11.164s\n",
 		},
-		"µs",
+		"s",
 		false,
 		false,
 	},
@@ -267,6 +267,6 @@ func Test_gray(t *testing.T) {
 	expected := "\033[90mfoo\033[0m"
 	actual := gray(s)
 	if expected != actual {
-		t.Errorf("Formatting gray string %: expected %v, actual %v", s, expected, actual)
+		t.Errorf("Formatting gray string %s: expected %v, actual %v", s, expected, actual)
 	}
 }
@@ -0,0 +1,208 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// minNoiseSamples is the number of samples needed to judge the noise of a benchmark
+	minNoiseSamples = 3
+	// minBimodalSamples is the number of samples needed to tell a bimodal distribution from chance
+	minBimodalSamples = 10
+	// maxStableCV is the largest coefficient of variation of a benchmark considered stable
+	maxStableCV = 0.05
+	// minBimodalCV is the smallest coefficient of variation for which two modes are worth mentioning
+	minBimodalCV = 0.01
+	// bimodalCoefficient is the bimodality coefficient of a uniform distribution, larger ones hint at two modes
+	bimodalCoefficient = 5.0 / 9
+	// tukeyFence is the number of interquartile ranges beyond the quartiles from which on samples are outliers
+	tukeyFence = 1.5
+
+	unstableMarker = "⚠"
+)
+
+// noiseNotes describes the unstable benchmarks of all rendered tables, printed in the footer
+var noiseNotes []string
+
+// noise describes how much the samples of a benchmark vary
+type noise struct {
+	samples  int
+	cv       float64
+	bimodal  bool
+	outliers int
+}
+
+// newNoise computes the noise diagnostics of samples, nil if there are too few of them
+func newNoise(samples []float64) *noise {
+	if len(samples) < minNoiseSamples {
+		return nil
+	}
+
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	n := &noise{samples: len(sorted)}
+
+	if m := mean(sorted); m != 0 {
+		n.cv = stddev(sorted) / math.Abs(m)
+	}
+
+	low, high := tukeyFences(sorted)
+	m := median(sorted)
+
+	var inliers []float64
+
+	for _, v := range sorted {
+		// with quantized timings the interquartile range is often 0, samples close to the median don't matter then
+		if (v < low || v > high) && math.Abs(v-m) > maxStableCV*math.Abs(m) {
+			n.outliers++
+			continue
+		}
+
+		inliers = append(inliers, v)
+	}
+
+	// a single outlier skews the distribution enough to look bimodal, so only the inliers are judged
+	n.bimodal = len(inliers) >= minBimodalSamples && stddev(inliers) >= minBimodalCV*math.Abs(mean(inliers)) &&
+		bimodality(inliers) > bimodalCoefficient
+
+	return n
+}
+
+func (n *noise) unstable() bool {
+	return n != nil && (n.cv > maxStableCV || n.bimodal || n.outliers > 0)
+}
+
+func (n *noise) String() string {
+	reasons := []string{"CV " + RenderFloat(fmtFloatUnit, n.cv*100) + "%"}
+
+	if n.bimodal {
+		reasons = append(reasons, "bimodal")
+	}
+
+	if n.outliers > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d of %d samples outliers", n.outliers, n.samples))
+	}
+
+	return strings.Join(reasons, ", ")
+}
+
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	m := mean(values)
+
+	var variance float64
+
+	for _, v := range values {
+		variance += (v - m) * (v - m)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+}
+
+// bimodality returns the sample bimodality coefficient (skewness² + 1) / kurtosis of values,
+// which exceeds 5/9 for bimodal distributions
+func bimodality(values []float64) float64 {
+	n := float64(len(values))
+	m := mean(values)
+
+	var m2, m3, m4 float64
+
+	for _, v := range values {
+		d := v - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+
+	m2, m3, m4 = m2/n, m3/n, m4/n
+
+	if m2 == 0 {
+		return 0
+	}
+
+	skewness := m3 / math.Pow(m2, 1.5) * math.Sqrt(n*(n-1)) / (n - 2)
+	excessKurtosis := ((n+1)*(m4/(m2*m2)-3) + 6) * (n - 1) / ((n - 2) * (n - 3))
+
+	return (skewness*skewness + 1) / (excessKurtosis + 3*(n-1)*(n-1)/((n-2)*(n-3)))
+}
+
+// tukeyFences returns the range of sorted values outside of which values are considered outliers
+func tukeyFences(sorted []float64) (float64, float64) {
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	iqr := q3 - q1
+
+	return q1 - tukeyFence*iqr, q3 + tukeyFence*iqr
+}
+
+// noiseMarker marks r's time if the samples of its benchmark are unstable
+func noiseMarker(bm *benchmark, r *result) string {
+	if !newNoise(sampleValues(bm, r, speed)).unstable() {
+		return ""
+	}
+
+	return " " + yellow(unstableMarker)
+}
+
+// noteNoise adds a note for every unstable benchmark of bm to the footer
+func noteNoise(bm *benchmark) {
+	names := make([]string, 0, len(*bm.results))
+
+	for name := range *bm.results {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		seen := make(map[resultKey]map[string]bool)
+
+		for _, r := range (*bm.results)[name] {
+			if seen[r.key()] == nil {
+				seen[r.key()] = make(map[string]bool)
+			}
+
+			if seen[r.key()][r.Source] {
+				continue
+			}
+
+			seen[r.key()][r.Source] = true
+
+			n := newNoise(sampleValues(bm, r, speed))
+
+			if !n.unstable() {
+				continue
+			}
+
+			note := displayName(r.Name, r.FnIterations, r.Procs)
+
+			if hasMultipleSources(bm) {
+				note += " (" + r.Source + ")"
+			}
+
+			noiseNotes = append(noiseNotes, note+": "+n.String())
+		}
+	}
+}
+
+// noiseFooter explains the unstable markers of the rendered tables
+func noiseFooter() string {
+	if len(noiseNotes) == 0 {
+		return ""
+	}
+
+	s := yellow(unstableMarker) + " unstable benchmarks, consider a higher -count or -benchtime:\n"
+
+	for _, note := range noiseNotes {
+		s += "  " + note + "\n"
+	}
+
+	return s
+}
@@ -0,0 +1,63 @@
+package prettybenchmarks
+
+import (
+	"testing"
+)
+
+func Test_newNoise(t *testing.T) {
+	for _, tt := range []struct {
+		samples  []float64
+		unstable bool
+		bimodal  bool
+		outliers int
+	}{
+		{[]float64{100, 101}, false, false, 0},
+		{[]float64{100, 101, 100, 99, 100}, false, false, 0},
+		{[]float64{100, 101, 100, 99, 100, 100, 101, 99, 100, 180}, true, false, 1},
+		{[]float64{100, 130, 80, 110, 95}, true, false, 0},
+		{[]float64{100, 100, 101, 100, 101, 120, 121, 120, 120, 121, 100, 120}, true, true, 0},
+	} {
+		n := newNoise(tt.samples)
+
+		if n.unstable() != tt.unstable {
+			t.Errorf("Judging noise of %v: expected unstable %v, actual %v", tt.samples, tt.unstable, n.unstable())
+		}
+
+		if n == nil {
+			continue
+		}
+
+		if n.bimodal != tt.bimodal || n.outliers != tt.outliers {
+			t.Errorf("Judging noise of %v: expected bimodal %v and %d outliers, actual %v and %d", tt.samples, tt.bimodal, tt.outliers, n.bimodal, n.outliers)
+		}
+	}
+}
+
+func Test_noteNoise(t *testing.T) {
+	defer func() {
+		colorEnabled = true
+		noiseNotes = nil
+	}()
+
+	colorEnabled = false
+	noiseNotes = nil
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Foo_10-8 	 100	 1000 ns/op\n"),
+		[]byte("Benchmark_Foo_10-8 	 100	 1300 ns/op\n"),
+		[]byte("Benchmark_Foo_10-8 	 100	 1100 ns/op\n"),
+		[]byte("Benchmark_Bar 	 100	 500 ns/op\n"),
+		[]byte("Benchmark_Bar 	 100	 501 ns/op\n"),
+		[]byte("Benchmark_Bar 	 100	 500 ns/op\n"),
+	})
+
+	noteNoise(bm)
+
+	if expected := "⚠ unstable benchmarks, consider a higher -count or -benchtime:\n  Foo_10-8: CV 13.48%\n"; noiseFooter() != expected {
+		t.Errorf("Noting noise: expected %q, actual %q", expected, noiseFooter())
+	}
+
+	if actual := noiseMarker(bm, (*bm.results)["Bar"][0]); actual != "" {
+		t.Errorf("Marking stable benchmark: expected no marker, actual %q", actual)
+	}
+}
@@ -0,0 +1,163 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	outliersOff    = ""
+	outliersNone   = "none"
+	outliersIQR    = "iqr"
+	outliersTrim   = "trim"
+	outliersMedian = "median"
+)
+
+// outlierMode defines how the samples of a benchmark are aggregated, see setOutlierMode
+var outlierMode = outliersOff
+
+// trimPercent is the share of samples dropped at either end in trim mode
+var trimPercent float64
+
+// setOutlierMode parses the --outliers flag: none aggregates samples to their mean, iqr drops samples
+// beyond the Tukey fences before, trim:X drops the X% fastest and slowest samples before (trimmed mean)
+// and median aggregates to the median of all samples. By default samples are only aggregated to their median
+// when comparing inputs, setting a mode aggregates them in every table
+func setOutlierMode(s string) error {
+	mode, percent := s, ""
+
+	if i := strings.Index(s, ":"); i >= 0 {
+		mode, percent = s[:i], s[i+1:]
+	}
+
+	switch mode {
+	case outliersOff, outliersNone, outliersIQR, outliersMedian:
+		if percent != "" {
+			return fmt.Errorf("invalid outlier mode %q, only trim takes a percentage", s)
+		}
+	case outliersTrim:
+		p, err := strconv.ParseFloat(strings.TrimSuffix(percent, "%"), 64)
+
+		if err != nil || p <= 0 || p >= 50 {
+			return fmt.Errorf("invalid trim percentage %q, use trim:X with 0 < X < 50", percent)
+		}
+
+		trimPercent = p
+	default:
+		return fmt.Errorf("invalid outlier mode %q, use none, iqr, trim:X or median", s)
+	}
+
+	outlierMode = mode
+
+	return nil
+}
+
+// discardsSamples reports whether the outlier mode drops samples, which are counted in the table then
+func discardsSamples(bm *benchmark) bool {
+	return outlierMode == outliersIQR || outlierMode == outliersTrim
+}
+
+// discardOutliers returns the samples of one benchmark which are kept by the outlier mode, judged by their time
+func discardOutliers(samples []*result) []*result {
+	switch outlierMode {
+	case outliersIQR:
+		times := values(samples, speed)
+		sort.Float64s(times)
+		low, high := tukeyFences(times)
+
+		var kept []*result
+
+		// samples without a time can't be outliers, their other metrics are kept
+		for _, r := range samples {
+			if r.Speed < 0 || r.Speed >= low && r.Speed <= high {
+				kept = append(kept, r)
+			}
+		}
+
+		return kept
+	case outliersTrim:
+		var sorted, unknown []*result
+
+		for _, r := range samples {
+			if r.Speed < 0 {
+				unknown = append(unknown, r)
+			} else {
+				sorted = append(sorted, r)
+			}
+		}
+
+		sort.Stable(sortBySpeed(sorted))
+
+		n := int(float64(len(sorted)) * trimPercent / 100)
+
+		if 2*n >= len(sorted) {
+			n = (len(sorted) - 1) / 2
+		}
+
+		if n < 0 {
+			n = 0
+		}
+
+		return append(sorted[n:len(sorted)-n], unknown...)
+	}
+
+	return samples
+}
+
+// center returns the function aggregating the values of the samples kept by the outlier mode. With
+// confidence intervals enabled it's always the median, the statistic the intervals are computed of
+func center() func(values []float64) float64 {
+	if outlierMode == outliersOff || outlierMode == outliersMedian || ciLevel != 0 {
+		return median
+	}
+
+	return mean
+}
+
+// aggregateBenchmark aggregates the samples of every benchmark of bm per input if an outlier mode is set
+// or confidence intervals are enabled. The samples are kept to judge their noise
+func aggregateBenchmark(bm *benchmark) *benchmark {
+	if outlierMode == outliersOff && ciLevel == 0 {
+		return bm
+	}
+
+	aggregated := make(results)
+
+	for name, rs := range *bm.results {
+		var groups [][]*result
+
+		for _, r := range rs {
+			found := false
+
+			for i, g := range groups {
+				if g[0].key() == r.key() && g[0].Source == r.Source {
+					groups[i] = append(g, r)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				groups = append(groups, []*result{r})
+			}
+		}
+
+		for _, g := range groups {
+			aggregated[name] = append(aggregated[name], aggregate(g))
+		}
+	}
+
+	return &benchmark{
+		info:    bm.info,
+		results: &aggregated,
+		samples: bm.results,
+	}
+}
+
+type sortBySpeed []*result
+
+func (s sortBySpeed) Len() int           { return len(s) }
+func (s sortBySpeed) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s sortBySpeed) Less(i, j int) bool { return s[i].Speed < s[j].Speed }
@@ -0,0 +1,104 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testOutlierLines = [][]byte{
+	[]byte("Benchmark_Foo-8 	 100	 1000 ns/op	 100 B/op	 2 allocs/op\n"),
+	[]byte("Benchmark_Foo-8 	 100	 1010 ns/op	 100 B/op	 2 allocs/op\n"),
+	[]byte("Benchmark_Foo-8 	 100	 990 ns/op	 100 B/op	 2 allocs/op\n"),
+	[]byte("Benchmark_Foo-8 	 100	 1020 ns/op	 100 B/op	 2 allocs/op\n"),
+	[]byte("Benchmark_Foo-8 	 100	 5000 ns/op	 900 B/op	 9 allocs/op\n"),
+}
+
+func Test_setOutlierMode(t *testing.T) {
+	defer setOutlierMode(outliersOff)
+
+	for _, tt := range []struct {
+		s    string
+		mode string
+		trim float64
+		err  bool
+	}{
+		{"", outliersOff, 0, false},
+		{"iqr", outliersIQR, 0, false},
+		{"trim:10", outliersTrim, 10, false},
+		{"trim:12.5%", outliersTrim, 12.5, false},
+		{"trim:50", "", 0, true},
+		{"trim", "", 0, true},
+		{"median:5", "", 0, true},
+		{"mean", "", 0, true},
+	} {
+		err := setOutlierMode(tt.s)
+
+		if (err != nil) != tt.err {
+			t.Errorf("Setting outlier mode %q: expected error %v, got %v", tt.s, tt.err, err)
+			continue
+		}
+
+		if err == nil && (outlierMode != tt.mode || (tt.mode == outliersTrim && trimPercent != tt.trim)) {
+			t.Errorf("Setting outlier mode %q: expected %q %v, actual %q %v", tt.s, tt.mode, tt.trim, outlierMode, trimPercent)
+		}
+	}
+}
+
+func Test_aggregateOutliers(t *testing.T) {
+	defer setOutlierMode(outliersOff)
+
+	samples := (*newResults(testOutlierLines))["Foo"]
+
+	for _, tt := range []struct {
+		mode      string
+		speed     float64
+		bps       float64
+		discarded int
+	}{
+		{outliersOff, 1010, 100, 0},
+		{outliersMedian, 1010, 100, 0},
+		{outliersNone, 1804, 260, 0},
+		{outliersIQR, 1005, 100, 1},
+		{"trim:20", 1010, 100, 2},
+	} {
+		if err := setOutlierMode(tt.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		agg := aggregate(samples)
+
+		if agg.Speed != tt.speed || agg.Bps != tt.bps || agg.Discarded != tt.discarded {
+			t.Errorf("Aggregating with outlier mode %q: expected %v ns/op, %v B/op, %d discarded, actual %v, %v, %d",
+				tt.mode, tt.speed, tt.bps, tt.discarded, agg.Speed, agg.Bps, agg.Discarded)
+		}
+	}
+}
+
+func Test_aggregateBenchmark(t *testing.T) {
+	defer setOutlierMode(outliersOff)
+
+	bm := newBenchmark(testOutlierLines)
+
+	if actual := aggregateBenchmark(bm); actual != bm {
+		t.Errorf("Aggregating without outlier mode: expected samples to be kept")
+	}
+
+	setOutlierMode(outliersIQR)
+
+	cols, err := selectColumns(bm, "name,discarded")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"name", "discarded"}; !reflect.DeepEqual(columnNames(cols), expected) {
+		t.Errorf("Selecting columns: expected %v, actual %v", expected, columnNames(cols))
+	}
+
+	agg := aggregateBenchmark(bm)
+	foo := (*agg.results)["Foo"]
+
+	if len(foo) != 1 || foo[0].Discarded != 1 || len(sampleValues(agg, foo[0], speed)) != 5 {
+		t.Errorf("Aggregating with outlier mode iqr: expected 1 result of 5 samples, 1 discarded, actual %#v", foo)
+	}
+}
@@ -0,0 +1,76 @@
+package prettybenchmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// outputFormat defines whether results are rendered as tables or JSON
+var outputFormat = formatTable
+
+func setOutputFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON:
+		outputFormat = format
+	case "":
+		outputFormat = formatTable
+	default:
+		return fmt.Errorf("invalid format %q, use %s or %s", format, formatTable, formatJSON)
+	}
+
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// jsonResults is the document printed by --format=json. Results are keyed by benchmark name, in compare and
+// separate mode by input label first, and Geomean holds the geometric mean of every metric over all results
+// (per input label). Deltas holds the geometric mean change in percent of every metric against the baseline
+// per input label in compare mode
+type jsonResults struct {
+	Results interface{}
+	Geomean interface{}                   `json:",omitempty"`
+	Deltas  map[string]map[string]float64 `json:",omitempty"`
+}
+
+// printResultsJSON prints the results read from the inputs as JSON
+func printResultsJSON(inputs []*input) error {
+	switch inputMode {
+	case inputsCompare:
+		cmp, err := newComparison(inputs, *baselineFlag)
+
+		if err != nil {
+			return err
+		}
+
+		return printJSON(cmp.jsonResults())
+	case inputsSeparate:
+		byLabel := make(map[string]*results, len(inputs))
+		geomeans := make(map[string]*result, len(inputs))
+
+		for _, in := range inputs {
+			bm := aggregateBenchmark(newBenchmark(in.lines))
+			byLabel[in.label] = bm.results
+			geomeans[in.label] = geomeanResult(allResults(bm))
+		}
+
+		return printJSON(&jsonResults{Results: byLabel, Geomean: geomeans})
+	}
+
+	return printJSON(newJSONResults(aggregateBenchmark(newMergedBenchmark(inputs))))
+}
+
+func newJSONResults(bm *benchmark) *jsonResults {
+	return &jsonResults{Results: bm.results, Geomean: geomeanResult(allResults(bm))}
+}
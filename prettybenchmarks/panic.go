@@ -0,0 +1,220 @@
+package prettybenchmarks
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// regExGoroutine matches the header of a goroutine in a stack trace, e.g. "goroutine 7 [running]:"
+	regExGoroutine = regexp.MustCompile(`^goroutine (\d+) .*\[([^\]]+)\]:$`)
+	// regExFrameOffset matches the program counter offset following the location of a frame
+	regExFrameOffset = regexp.MustCompile(`\s+\+0x[0-9a-f]+$`)
+)
+
+// benchmarkPanic holds the output of a panic, attributed to the benchmark running when it occurred
+type benchmarkPanic struct {
+	name  string
+	lines []string
+}
+
+// benchmarkPanics holds the panics of all inputs which occurred while a benchmark was running
+var benchmarkPanics []*benchmarkPanic
+
+// frame is a function call of a goroutine's stack, its location shortened to file:line
+type frame struct {
+	fn       string
+	location string
+}
+
+// goroutine is a goroutine of a stack trace, goroutines with identical stacks are merged into one
+type goroutine struct {
+	ids    []string
+	state  string
+	frames []*frame
+}
+
+// panicTrace is the condensed form of a panic's output: its messages and its deduplicated goroutines
+type panicTrace struct {
+	name       string
+	messages   []string
+	goroutines []*goroutine
+}
+
+// newPanicTrace condenses the lines go printed for a panic of the benchmark with the given name,
+// which is empty if the panic occurred outside of any benchmark
+func newPanicTrace(name string, lines []string) *panicTrace {
+	t := &panicTrace{name: name}
+
+	var (
+		current *goroutine
+		stacks  = make(map[string]*goroutine)
+	)
+
+	merge := func() {
+		if current == nil {
+			return
+		}
+
+		key := current.state
+
+		for _, f := range current.frames {
+			key += "\n" + f.fn + " " + f.location
+		}
+
+		if g, ok := stacks[key]; ok {
+			g.ids = append(g.ids, current.ids...)
+		} else {
+			stacks[key] = current
+			t.goroutines = append(t.goroutines, current)
+		}
+
+		current = nil
+	}
+
+	for _, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
+		trimmed := strings.TrimSpace(line)
+
+		if m := regExGoroutine.FindStringSubmatch(trimmed); m != nil {
+			merge()
+			current = &goroutine{ids: []string{m[1]}, state: m[2]}
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+		case current == nil:
+			t.messages = append(t.messages, trimmed)
+		case strings.HasPrefix(line, "\t") && len(current.frames) > 0:
+			f := current.frames[len(current.frames)-1]
+			location := regExFrameOffset.ReplaceAllString(trimmed, "")
+			f.location = filepath.Base(location)
+		default:
+			current.frames = append(current.frames, &frame{fn: frameFunction(trimmed)})
+		}
+	}
+
+	merge()
+
+	return t
+}
+
+// frameFunction strips the arguments from a function line of a stack trace, e.g. "pkg.(*T).M(0x1, 0x2)"
+func frameFunction(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+		return line[:i]
+	}
+
+	return line
+}
+
+// framePackage returns the import path of the package fn belongs to, e.g. "net/http" for "net/http.(*conn).serve"
+func framePackage(fn string) string {
+	fn = strings.TrimPrefix(fn, "created by ")
+
+	if i := strings.Index(fn, " in goroutine "); i >= 0 {
+		fn = fn[:i]
+	}
+
+	slash := strings.LastIndex(fn, "/")
+	dot := strings.Index(fn[slash+1:], ".")
+
+	if dot < 0 {
+		return ""
+	}
+
+	return fn[:slash+1+dot]
+}
+
+// isStdlibPackage reports whether pkg belongs to the standard library, whose import paths have no dot
+// in their first element
+func isStdlibPackage(pkg string) bool {
+	if pkg == "" || pkg == "main" {
+		return false
+	}
+
+	return !strings.Contains(strings.Split(pkg, "/")[0], ".")
+}
+
+// ownPackages returns the packages of the benchmark functions in t's stacks, along with the package they
+// test if they are part of an external test package
+func (t *panicTrace) ownPackages() map[string]bool {
+	own := make(map[string]bool)
+
+	for _, g := range t.goroutines {
+		for _, f := range g.frames {
+			pkg := framePackage(f.fn)
+
+			if pkg == "" || !strings.HasPrefix(strings.TrimPrefix(f.fn, pkg+"."), "Benchmark") {
+				continue
+			}
+
+			own[pkg] = true
+			own[strings.TrimSuffix(pkg, "_test")] = true
+		}
+	}
+
+	return own
+}
+
+// lines renders the trace: frames of the benchmarked packages are highlighted, standard library frames dimmed
+func (t *panicTrace) lines() []string {
+	var out []string
+
+	messages := t.messages
+
+	if t.name != "" {
+		out = append(out, red(bold("--- PANIC"))+" "+bold(t.name))
+	} else if len(messages) > 0 {
+		out = append(out, red(bold(messages[0])))
+		messages = messages[1:]
+	}
+
+	for _, m := range messages {
+		out = append(out, "  "+red(m))
+	}
+
+	own := t.ownPackages()
+
+	for _, g := range t.goroutines {
+		header := "goroutine " + g.ids[0]
+
+		if len(g.ids) > 1 {
+			header = "goroutines " + strings.Join(g.ids, ", ")
+		}
+
+		out = append(out, "  "+header+" ["+g.state+"]:")
+
+		for _, f := range g.frames {
+			line := f.fn
+
+			if f.location != "" {
+				line += " " + f.location
+			}
+
+			switch pkg := framePackage(f.fn); {
+			case own[pkg]:
+				line = bold(line)
+			case isStdlibPackage(pkg):
+				line = gray(line)
+			}
+
+			out = append(out, "    "+line)
+		}
+	}
+
+	return out
+}
+
+// hasPanicked reports whether the benchmark with the given (normalized) name panicked
+func hasPanicked(name string) bool {
+	for _, p := range benchmarkPanics {
+		if p.name == name || regExIterSuffix.ReplaceAllString(p.name, "") == name {
+			return true
+		}
+	}
+
+	return false
+}
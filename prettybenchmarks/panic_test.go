@@ -0,0 +1,144 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testPanicOutput = []string{
+	"goos: linux",
+	"BenchmarkFine-8   \t 1000000\t      1052 ns/op",
+	"BenchmarkBoom_100-8   \tpanic: runtime error: index out of range [3] with length 3",
+	"",
+	"goroutine 8 [running]:",
+	"example.com/foo.lookup(...)",
+	"\t/src/foo/foo.go:12",
+	"example.com/foo_test.BenchmarkBoom_100(0xc000132000)",
+	"\t/src/foo/foo_test.go:20 +0x1d",
+	"testing.(*B).runN(0xc000132000, 0x1)",
+	"\t/usr/lib/go/src/testing/benchmark.go:193 +0x102",
+	"created by testing.(*B).run1 in goroutine 1",
+	"\t/usr/lib/go/src/testing/benchmark.go:233 +0x86",
+	"",
+	"goroutine 9 [chan receive]:",
+	"github.com/x/pool.(*Pool).wait(0xc000010000)",
+	"\t/go/pkg/mod/github.com/x/pool/pool.go:40 +0x2b",
+	"",
+	"goroutine 10 [chan receive]:",
+	"github.com/x/pool.(*Pool).wait(0xc000010008)",
+	"\t/go/pkg/mod/github.com/x/pool/pool.go:40 +0x2b",
+	"exit status 2",
+	"FAIL\texample.com/foo\t0.512s",
+}
+
+func Test_parsePanic(t *testing.T) {
+	p := &lineParser{}
+
+	var unparsable []string
+
+	for _, line := range testPanicOutput {
+		if _, err := p.parse([]byte(line)); err != nil {
+			unparsable = append(unparsable, err.Error())
+		}
+	}
+
+	if len(p.panics) != 1 || p.panics[0].name != "Boom_100" {
+		t.Fatalf("Attributing panic: expected one panic of Boom_100, actual %#v", p.panics)
+	}
+
+	if !reflect.DeepEqual(p.panics[0].lines, testPanicTrace) {
+		t.Errorf("Collecting stack trace: expected %q, actual %q", testPanicTrace, p.panics[0].lines)
+	}
+
+	expected := []string{"goos: linux", "exit status 2", "FAIL\texample.com/foo\t0.512s"}
+
+	if !reflect.DeepEqual(unparsable, expected) {
+		t.Errorf("Lines after panic: expected %q, actual %q", expected, unparsable)
+	}
+
+	if len(p.logs) != 0 {
+		t.Errorf("Stack trace logged as benchmark output: %#v", p.logs[0])
+	}
+}
+
+// testPanicTrace are the lines of testPanicOutput the parser attributes to the panic
+var testPanicTrace = append(
+	[]string{"panic: runtime error: index out of range [3] with length 3"},
+	testPanicOutput[3:len(testPanicOutput)-2]...,
+)
+
+func Test_newPanicTrace(t *testing.T) {
+	trace := newPanicTrace("Boom_100", testPanicTrace)
+
+	if !reflect.DeepEqual(trace.messages, []string{"panic: runtime error: index out of range [3] with length 3"}) {
+		t.Errorf("Parsing messages: actual %q", trace.messages)
+	}
+
+	if len(trace.goroutines) != 2 {
+		t.Fatalf("Deduplicating goroutines: expected 2, actual %d", len(trace.goroutines))
+	}
+
+	expected := &goroutine{
+		ids:   []string{"9", "10"},
+		state: "chan receive",
+		frames: []*frame{
+			{"github.com/x/pool.(*Pool).wait", "pool.go:40"},
+		},
+	}
+
+	if !reflect.DeepEqual(trace.goroutines[1], expected) {
+		t.Errorf("Merging goroutines: expected %#v, actual %#v", expected, trace.goroutines[1])
+	}
+}
+
+func Test_panicTraceLines(t *testing.T) {
+	defer func() { colorEnabled = true }()
+
+	colorEnabled = false
+
+	expected := []string{
+		"--- PANIC Boom_100",
+		"  panic: runtime error: index out of range [3] with length 3",
+		"  goroutine 8 [running]:",
+		"    example.com/foo.lookup foo.go:12",
+		"    example.com/foo_test.BenchmarkBoom_100 foo_test.go:20",
+		"    testing.(*B).runN benchmark.go:193",
+		"    created by testing.(*B).run1 in goroutine 1 benchmark.go:233",
+		"  goroutines 9, 10 [chan receive]:",
+		"    github.com/x/pool.(*Pool).wait pool.go:40",
+	}
+
+	actual := newPanicTrace("Boom_100", testPanicTrace).lines()
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Rendering trace: expected\n%s\nactual\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func Test_framePackage(t *testing.T) {
+	tests := []struct {
+		fn     string
+		pkg    string
+		stdlib bool
+	}{
+		{"net/http.(*conn).serve", "net/http", true},
+		{"testing.(*B).runN", "testing", true},
+		{"created by testing.(*B).run1 in goroutine 1", "testing", true},
+		{"github.com/x/pool.(*Pool).wait", "github.com/x/pool", false},
+		{"main.main", "main", false},
+		{"...additional frames elided...", "", false},
+	}
+
+	for _, test := range tests {
+		pkg := framePackage(test.fn)
+
+		if pkg != test.pkg {
+			t.Errorf("Package of %s: expected %q, actual %q", test.fn, test.pkg, pkg)
+		}
+
+		if actual := isStdlibPackage(pkg); actual != test.stdlib {
+			t.Errorf("Checking if %s is stdlib: expected %v, actual %v", pkg, test.stdlib, actual)
+		}
+	}
+}
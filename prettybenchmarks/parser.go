@@ -0,0 +1,193 @@
+package prettybenchmarks
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// regExMeasurement matches the measurements go test prints after a benchmark finished, possibly
+	// preceded by output of the benchmark itself. Malformed runs and ns/op still match to be diagnosed by parseResult
+	regExMeasurement = regexp.MustCompile(`^(.*?)\s*(\S+\s+\S+ ns/op.*)$`)
+	// regExBenchmarkBlock matches the headers go test prints before the log output of a benchmark
+	regExBenchmarkBlock = regexp.MustCompile(`^--- (BENCH|FAIL|SKIP): (\S+)`)
+	// regExEndOfBenchmark matches lines which end the output of the running benchmark
+	regExEndOfBenchmark = regexp.MustCompile(`^(PASS|FAIL|SKIP|ok\s|---\s|panic:|exit status)`)
+)
+
+// benchmarkLog holds the lines a benchmark logged via b.Log or printed, in the order they were read
+type benchmarkLog struct {
+	name  string
+	lines []string
+}
+
+// benchmarkLogs holds the output of all benchmarks which logged or printed anything
+var benchmarkLogs []*benchmarkLog
+
+// lineParser parses benchmark output line by line. With -v or benchmarks writing to stdout, go test prints
+// a benchmark's name and its measurements on different lines with the benchmark's output in between,
+// so the parser keeps track of the benchmark running and attributes such lines to it
+type lineParser struct {
+	// pending is the name of the benchmark printed without its measurements yet
+	pending string
+	// current is the benchmark following indented lines belong to, e.g. after --- BENCH: BenchmarkX
+	current string
+	logs    []*benchmarkLog
+	// panicking is set while reading the stack trace of a panic of the pending benchmark
+	panicking bool
+	panics    []*benchmarkPanic
+	// line is the number of the line parsed last, diagnostics the malformed values found so far
+	line        int
+	diagnostics []*diagnostic
+}
+
+// parse parses one line: it returns the result of a complete benchmark line, nil if the line was consumed
+// as name of a pending benchmark or as its output, and an error if the line is no benchmark output at all
+func (p *lineParser) parse(line []byte) (*result, error) {
+	s := strings.TrimRight(string(line), "\r\n")
+	fields := strings.Fields(s)
+	p.line++
+
+	if p.panicking {
+		// the stack trace ends with the exit status of the test binary or the status of the package
+		if trimmed := strings.TrimSpace(s); !isStatusLine(trimmed) && !regExPackage.MatchString(trimmed) {
+			last := p.panics[len(p.panics)-1]
+			last.lines = append(last.lines, s)
+			return nil, nil
+		}
+
+		p.panicking, p.pending, p.current = false, "", ""
+	}
+
+	if len(fields) > 0 && regExIsBenchmark.MatchString(fields[0]) {
+		p.pending, p.current = fields[0], ""
+		rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), fields[0]))
+
+		if r := p.measure(rest, len(strings.TrimRightFunc(s, unicode.IsSpace))-len(rest)); r != nil {
+			return r, nil
+		}
+
+		if regExPanic.MatchString(rest) {
+			p.startPanic(rest)
+			return nil, nil
+		}
+
+		p.addLog(rest)
+
+		return nil, nil
+	}
+
+	if m := regExBenchmarkBlock.FindStringSubmatch(s); m != nil {
+		p.pending, p.current = "", normalizeName(m[2])
+
+		// the log lines following are shown below the benchmark's name, failures and skips stay in the summary
+		if m[1] == "BENCH" {
+			return nil, nil
+		}
+		return nil, errors.New(s)
+	}
+
+	if p.pending != "" {
+		if regExPanic.MatchString(strings.TrimSpace(s)) {
+			p.startPanic(strings.TrimSpace(s))
+			return nil, nil
+		}
+
+		if r := p.measure(s, 0); r != nil {
+			return r, nil
+		}
+
+		if !regExEndOfBenchmark.MatchString(s) {
+			p.addLog(strings.TrimSpace(s))
+			return nil, nil
+		}
+	}
+
+	if p.current != "" && (strings.HasPrefix(s, " ") || strings.HasPrefix(s, "\t")) {
+		p.addLog(strings.TrimSpace(s))
+		return nil, nil
+	}
+
+	p.pending, p.current = "", ""
+
+	return newResult(line)
+}
+
+// measure parses the measurements of the pending benchmark at the end of s, anything before them is its output.
+// offset is the position of s in the line read, used to locate diagnostics. It returns nil if s holds no measurements
+func (p *lineParser) measure(s string, offset int) *result {
+	m := regExMeasurement.FindStringSubmatch(s)
+
+	if m == nil {
+		return nil
+	}
+
+	prefix := p.pending + "\t"
+	r, diags, err := parseResult([]byte(prefix + m[2]))
+
+	if err != nil {
+		return nil
+	}
+
+	// columns are relative to the line parsed, which starts with the pending name instead of s up to the measurements
+	shift := offset + strings.LastIndex(s, m[2]) - len(prefix)
+
+	for _, d := range diags {
+		d.line, d.column = p.line, d.column+shift
+		p.diagnostics = append(p.diagnostics, d)
+	}
+
+	p.addLog(strings.TrimSpace(m[1]))
+	p.current, p.pending = normalizeName(p.pending), ""
+
+	return r
+}
+
+// startPanic attributes a panic and the stack trace following it to the pending benchmark
+func (p *lineParser) startPanic(line string) {
+	p.panics = append(p.panics, &benchmarkPanic{normalizeName(p.pending), []string{line}})
+	p.panicking = true
+}
+
+// addLog attributes a line of output to the pending or current benchmark
+func (p *lineParser) addLog(line string) {
+	name := p.current
+
+	if p.pending != "" {
+		name = normalizeName(p.pending)
+	}
+
+	if line == "" || name == "" {
+		return
+	}
+
+	for _, l := range p.logs {
+		if l.name == name {
+			l.lines = append(l.lines, line)
+			return
+		}
+	}
+
+	p.logs = append(p.logs, &benchmarkLog{name, []string{line}})
+}
+
+// addBenchmarkLogs adds logs to the ones of all inputs, merging the logs of equally named benchmarks
+func addBenchmarkLogs(logs []*benchmarkLog) {
+	for _, l := range logs {
+		merged := false
+
+		for _, existing := range benchmarkLogs {
+			if existing.name == l.name {
+				existing.lines = append(existing.lines, l.lines...)
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			benchmarkLogs = append(benchmarkLogs, l)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_lineParser(t *testing.T) {
+	p := &lineParser{}
+
+	var (
+		parsed     []string
+		unparsable []string
+		speeds     []float64
+		input      = []string{
+			"goos: linux\n",
+			"BenchmarkVerbose\n",
+			"    verbose_test.go:12: starting\n",
+			"BenchmarkVerbose-8   \t     100\t      1000 ns/op\n",
+			"BenchmarkPrinting_10-8   \thello from the benchmark\n",
+			"     200\t      2000 ns/op\t      16 B/op\t       1 allocs/op\n",
+			"BenchmarkInline-8   \tno newline     300\t      3000 ns/op\n",
+			"--- BENCH: BenchmarkVerbose-8\n",
+			"    verbose_test.go:14: done\n",
+			"BenchmarkFailing\n",
+			"--- FAIL: BenchmarkFailing\n",
+			"    failing_test.go:8: boom\n",
+			"FAIL\n",
+		}
+	)
+
+	for _, line := range input {
+		r, err := p.parse([]byte(line))
+
+		switch {
+		case err != nil:
+			unparsable = append(unparsable, err.Error())
+		case r != nil:
+			parsed = append(parsed, r.Name)
+			speeds = append(speeds, r.Speed)
+		}
+	}
+
+	if expected := []string{"Verbose", "Printing", "Inline"}; !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Parsing interleaved output: expected results %v, actual %v", expected, parsed)
+	}
+
+	if expected := []float64{1000, 2000, 3000}; !reflect.DeepEqual(speeds, expected) {
+		t.Errorf("Parsing interleaved output: expected speeds %v, actual %v", expected, speeds)
+	}
+
+	if expected := []string{"goos: linux\n", "--- FAIL: BenchmarkFailing", "FAIL\n"}; !reflect.DeepEqual(unparsable, expected) {
+		t.Errorf("Parsing interleaved output: expected unparsable lines %q, actual %q", expected, unparsable)
+	}
+
+	logs := make(map[string][]string)
+
+	for _, l := range p.logs {
+		logs[l.name] = l.lines
+	}
+
+	expectedLogs := map[string][]string{
+		"Verbose":     {"verbose_test.go:12: starting", "verbose_test.go:14: done"},
+		"Printing_10": {"hello from the benchmark"},
+		"Inline":      {"no newline"},
+		"Failing":     {"failing_test.go:8: boom"},
+	}
+
+	if !reflect.DeepEqual(logs, expectedLogs) {
+		t.Errorf("Parsing interleaved output: expected logs %q, actual %q", expectedLogs, logs)
+	}
+}
@@ -0,0 +1,175 @@
+package prettybenchmarks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// runLabel refers to the output of go test in diagnostics
+const runLabel = "go test"
+
+// defaultRunArgs are passed to go test by 'pb run' before the user's flags, which may override them
+var defaultRunArgs = []string{"-run=^$", "-bench=.", "-benchmem"}
+
+// exitStatusError makes pb exit with the given status instead of 1, e.g. the one of go test
+type exitStatusError struct {
+	status int
+	err    error
+}
+
+func (e *exitStatusError) Error() string {
+	return e.err.Error()
+}
+
+// exitStatus returns the status pb should exit with for err
+func exitStatus(err error) int {
+	if e, ok := err.(*exitStatusError); ok {
+		return e.status
+	}
+
+	return 1
+}
+
+// runCommand implements 'pb run [packages] [-- go test flags]': it runs the benchmarks of the packages
+// (default .) itself, shows the progress while they run and renders the results
+func runCommand(args []string) error {
+	packages, flags := splitArgs(args)
+
+	if len(packages) == 0 {
+		packages = []string{"."}
+	}
+
+	testArgs := append(append(append([]string{}, defaultRunArgs...), flags...), packages...)
+	l, err := goTest(".", testArgs)
+
+	lines = append(lines, l...)
+	bench = aggregateBenchmark(newBenchmark(l))
+
+	diagnostics := inputDiagnostics([]*input{{runLabel, l}})
+	printDiagnostics(os.Stderr, diagnostics)
+
+	// a failing go test takes precedence over malformed lines
+	if err == nil {
+		err = strictError(diagnostics)
+	}
+
+	if outputFormat == formatJSON {
+		if jsonErr := printJSON(newJSONResults(bench)); jsonErr != nil {
+			return jsonErr
+		}
+		return err
+	}
+
+	if len(*bench.results) > 0 {
+		printTable(bench)
+	}
+
+	fmt.Println(footer())
+
+	return err
+}
+
+// splitArgs splits args at "--" into the arguments for pb and the flags passed through to go test
+func splitArgs(args []string) ([]string, []string) {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+
+	return args, nil
+}
+
+// goTest runs go test with the given arguments in dir, showing its progress on stderr if it is a terminal.
+// It returns the lines go test printed to stdout followed by the ones printed to stderr
+func goTest(dir string, args []string) ([][]byte, error) {
+	var stderr bytes.Buffer
+
+	progress := &progressWriter{}
+
+	if progressEnabled {
+		progress.status = os.Stderr
+	}
+
+	cmd := exec.Command("go", append([]string{"test"}, args...)...)
+	cmd.Dir = dir
+	cmd.Stdout = progress
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	progress.clear()
+
+	l, _ := readLines(&progress.out)
+	errLines, _ := readLines(&stderr)
+	l = append(l, errLines...)
+
+	if err != nil {
+		status := 1
+
+		if e, ok := err.(*exec.ExitError); ok {
+			if ws, ok := e.Sys().(syscall.WaitStatus); ok {
+				status = ws.ExitStatus()
+			}
+		}
+
+		return l, &exitStatusError{status, fmt.Errorf("go test %s: %s", strings.Join(args, " "), err)}
+	}
+
+	return l, nil
+}
+
+// progressWriter collects the output of go test and reports the number of finished benchmarks
+// and the one currently running, which go test prints before measuring it
+type progressWriter struct {
+	out     bytes.Buffer
+	partial []byte
+	done    int
+	status  io.Writer
+	parser  lineParser
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.out.Write(b)
+
+	for _, c := range b {
+		if c != '\n' {
+			p.partial = append(p.partial, c)
+			continue
+		}
+
+		if r, err := p.parser.parse(p.partial); err == nil && r != nil {
+			p.done++
+		}
+
+		p.partial = p.partial[:0]
+	}
+
+	p.report()
+
+	return len(b), nil
+}
+
+func (p *progressWriter) report() {
+	if p.status == nil {
+		return
+	}
+
+	msg := fmt.Sprintf("%d benchmarks done", p.done)
+
+	if fields := strings.Fields(string(p.partial)); len(fields) > 0 && regExIsBenchmark.MatchString(fields[0]) {
+		msg += ", running " + normalizeName(fields[0])
+	}
+
+	fmt.Fprintf(p.status, "\r\033[K%s", msg)
+}
+
+func (p *progressWriter) clear() {
+	if p.status != nil {
+		fmt.Fprint(p.status, "\r\033[K")
+	}
+}
@@ -0,0 +1,62 @@
+package prettybenchmarks
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_splitArgs(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		packages []string
+		flags    []string
+	}{
+		{nil, nil, nil},
+		{[]string{"./..."}, []string{"./..."}, nil},
+		{[]string{"./foo", "./bar", "--", "-count=5"}, []string{"./foo", "./bar"}, []string{"-count=5"}},
+		{[]string{"--", "-bench=Foo"}, []string{}, []string{"-bench=Foo"}},
+	} {
+		packages, flags := splitArgs(tt.args)
+
+		if !reflect.DeepEqual(packages, tt.packages) || !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("Splitting %v: expected %v %v, actual %v %v", tt.args, tt.packages, tt.flags, packages, flags)
+		}
+	}
+}
+
+func Test_progressWriter(t *testing.T) {
+	var status bytes.Buffer
+
+	p := &progressWriter{status: &status}
+
+	for _, chunk := range []string{
+		"goos: linux\nBenchmarkFoo-8   \t",
+		"  100\t  1000 ns/op\nBenchmark_Bar_10",
+	} {
+		p.Write([]byte(chunk))
+	}
+
+	if p.done != 1 {
+		t.Errorf("Counting finished benchmarks: expected 1, actual %d", p.done)
+	}
+
+	if expected := "\r\033[K1 benchmarks done, running Bar_10"; !bytes.HasSuffix(status.Bytes(), []byte(expected)) {
+		t.Errorf("Reporting progress: expected %q, actual %q", expected, status.String())
+	}
+
+	if expected := "goos: linux\nBenchmarkFoo-8   \t  100\t  1000 ns/op\nBenchmark_Bar_10"; p.out.String() != expected {
+		t.Errorf("Collecting output: expected %q, actual %q", expected, p.out.String())
+	}
+}
+
+func Test_exitStatus(t *testing.T) {
+	if actual := exitStatus(&exitStatusError{2, errors.New("failed")}); actual != 2 {
+		t.Errorf("Getting exit status: expected 2, actual %d", actual)
+	}
+
+	if actual := exitStatus(errors.New("failed")); actual != 1 {
+		t.Errorf("Getting exit status: expected 1, actual %d", actual)
+	}
+}
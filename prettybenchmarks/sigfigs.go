@@ -0,0 +1,112 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	sigFigsOff  = 0
+	sigFigsAuto = -1
+
+	// defaultSigFigs is used in auto mode when there are not enough samples to estimate the variance
+	defaultSigFigs = 3
+	maxSigFigs     = 6
+)
+
+// sigFigs is the number of significant digits metrics are rendered with, see setSigFigs
+var sigFigs = sigFigsOff
+
+// setSigFigs parses the --sigfigs flag: an empty value keeps the fixed precision of each column,
+// a number renders metrics with that many significant digits and "auto" derives them from
+// the variance of the samples of each benchmark
+func setSigFigs(s string) error {
+	switch s {
+	case "", "0":
+		sigFigs = sigFigsOff
+	case "auto":
+		sigFigs = sigFigsAuto
+	default:
+		n, err := strconv.Atoi(s)
+
+		if err != nil || n < 1 || n > maxSigFigs {
+			return fmt.Errorf("invalid number of significant digits %q, use 1-%d or auto", s, maxSigFigs)
+		}
+
+		sigFigs = n
+	}
+
+	return nil
+}
+
+// renderMetric renders v according to format, honoring the significant digits mode.
+// samples are all measured values of v's benchmark (in any unit), used in auto mode
+func renderMetric(format string, v float64, samples []float64) string {
+	switch sigFigs {
+	case sigFigsOff:
+		return RenderFloat(format, v)
+	case sigFigsAuto:
+		return RenderSignificant(format, v, digitsFromVariance(samples))
+	}
+
+	return RenderSignificant(format, v, sigFigs)
+}
+
+// digitsFromVariance returns the number of significant digits which are not dominated by the noise of the
+// samples, i.e. the digits of the mean down to the magnitude of the standard deviation
+func digitsFromVariance(samples []float64) int {
+	if len(samples) < 2 {
+		return defaultSigFigs
+	}
+
+	var mean, variance float64
+
+	for _, s := range samples {
+		mean += s
+	}
+
+	mean /= float64(len(samples))
+
+	for _, s := range samples {
+		variance += (s - mean) * (s - mean)
+	}
+
+	stddev := math.Sqrt(variance / float64(len(samples)-1))
+
+	if stddev == 0 || mean == 0 {
+		return maxSigFigs
+	}
+
+	digits := int(math.Floor(math.Log10(math.Abs(mean)))-math.Floor(math.Log10(stddev))) + 1
+
+	switch {
+	case digits < 1:
+		return 1
+	case digits > maxSigFigs:
+		return maxSigFigs
+	}
+
+	return digits
+}
+
+// sampleValues collects value of every sample of the same benchmark as r, i.e. all results
+// with equal name, iterations and procs as produced by go test -count, read from the same input.
+// If bm's results are aggregated, the samples they were aggregated from are used. Missing values are skipped
+func sampleValues(bm *benchmark, r *result, value func(r *result) float64) []float64 {
+	var samples []float64
+
+	rs := bm.results
+
+	if bm.samples != nil {
+		rs = bm.samples
+	}
+
+	for _, s := range (*rs)[r.Name] {
+		if v := value(s); s.key() == r.key() && s.Source == r.Source && v >= 0 {
+			samples = append(samples, v)
+		}
+	}
+
+	return samples
+}
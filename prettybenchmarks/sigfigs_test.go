@@ -0,0 +1,53 @@
+package prettybenchmarks
+
+import "testing"
+
+func Test_digitsFromVariance(t *testing.T) {
+	for _, tt := range []struct {
+		samples  []float64
+		expected int
+	}{
+		{nil, defaultSigFigs},
+		{[]float64{109.805}, defaultSigFigs},
+		{[]float64{109.805, 109.805}, maxSigFigs},
+		{[]float64{100, 110, 120}, 2},
+		{[]float64{1000, 1001, 1002}, 4},
+		{[]float64{1, 100}, 1},
+	} {
+		if actual := digitsFromVariance(tt.samples); actual != tt.expected {
+			t.Errorf("Deriving significant digits for %v: expected %v, actual %v", tt.samples, tt.expected, actual)
+		}
+	}
+}
+
+func Test_formatTimeSigFigs(t *testing.T) {
+	defer func() { sigFigs = sigFigsOff }()
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Fast-8      	  100000	     1098 ns/op\n"),
+		[]byte("Benchmark_Fast-8      	  100000	     1102 ns/op\n"),
+		[]byte("Benchmark_Fast-8      	  100000	     1131 ns/op\n"),
+	})
+	bm.info.suggestedTiming = "µs"
+
+	for _, tt := range []struct {
+		sigFigs  string
+		expected string
+	}{
+		{"", "1.098"},
+		{"2", "1.1"},
+		{"auto", "1.10"},
+	} {
+		if err := setSigFigs(tt.sigFigs); err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := formatTime(bm, (*bm.results)["Fast"][0], true); actual != tt.expected {
+			t.Errorf("Formatting time with significant digits %q: expected %v, actual %v", tt.sigFigs, tt.expected, actual)
+		}
+	}
+
+	if err := setSigFigs("many"); err == nil {
+		t.Errorf("Setting significant digits %q: expected error", "many")
+	}
+}
@@ -0,0 +1,254 @@
+package prettybenchmarks
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// alpha is the significance level below which a difference between two sets of samples is reported
+	alpha = 0.05
+	// exactMannWhitneyLimit is the largest number of samples for which the exact distribution of U is computed
+	exactMannWhitneyLimit = 40
+)
+
+// aggregate combines the samples of one benchmark (equal name, iterations and procs) into a single
+// result holding the median of every metric, or the mean of the samples kept by the outlier mode.
+// If enabled, the confidence intervals of their medians are added and the median is taken in any case
+func aggregate(samples []*result) *result {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	kept := discardOutliers(samples)
+	first := samples[0]
+	agg := &result{
+		Name:         first.Name,
+		FnIterations: first.FnIterations,
+		Procs:        first.Procs,
+		Source:       first.Source,
+		Runs:         int(aggregateOf(kept, func(r *result) float64 { return float64(r.Runs) })),
+		Speed:        aggregateOf(kept, speed),
+		Bps:          aggregateOf(kept, bytesPerOp),
+		Aps:          aggregateOf(kept, allocsPerOp),
+		Discarded:    len(samples) - len(kept),
+		Intervals:    intervals(kept),
+	}
+
+	for _, r := range kept {
+		for unit := range r.Metrics {
+			if _, ok := agg.Metrics[unit]; ok {
+				continue
+			}
+
+			if agg.Metrics == nil {
+				agg.Metrics = make(map[string]float64)
+			}
+
+			var values []float64
+
+			for _, s := range kept {
+				if v, ok := s.Metrics[unit]; ok {
+					values = append(values, v)
+				}
+			}
+
+			agg.Metrics[unit] = center()(values)
+		}
+	}
+
+	return agg
+}
+
+// aggregateOf aggregates the known values of samples, -1 if all of them are missing
+func aggregateOf(samples []*result, value func(r *result) float64) float64 {
+	vs := values(samples, value)
+
+	if len(vs) == 0 {
+		return -1
+	}
+
+	return center()(vs)
+}
+
+// medianOf returns the median of the known values of samples, -1 if all of them are missing
+func medianOf(samples []*result, value func(r *result) float64) float64 {
+	vs := values(samples, value)
+
+	if len(vs) == 0 {
+		return -1
+	}
+
+	return median(vs)
+}
+
+// values collects value of every result, skipping the negative values which stand for missing or malformed fields
+func values(rs []*result, value func(r *result) float64) []float64 {
+	vs := make([]float64, 0, len(rs))
+
+	for _, r := range rs {
+		if v := value(r); v >= 0 {
+			vs = append(vs, v)
+		}
+	}
+
+	return vs
+}
+
+// median returns the median of values, 0 if there are none
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	m := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[m-1] + sorted[m]) / 2
+	}
+
+	return sorted[m]
+}
+
+// quantile returns the q-quantile (0 <= q <= 1) of sorted values, interpolating linearly between them
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U test, i.e. the probability of seeing a
+// difference in ranks at least as large as between x and y if both were drawn from the same distribution.
+// Small samples without ties use the exact distribution of U, others the normal approximation
+func mannWhitneyU(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	all := make(sortByValue, 0, n1+n2)
+
+	for _, v := range x {
+		all = append(all, observation{v, true})
+	}
+
+	for _, v := range y {
+		all = append(all, observation{v, false})
+	}
+
+	sort.Sort(all)
+
+	var (
+		rankSumX float64
+		tieSum   float64
+		n        = len(all)
+	)
+
+	for i := 0; i < n; {
+		j := i
+
+		for j < n && all[j].value == all[i].value {
+			j++
+		}
+
+		// tied values get the average of their ranks
+		rank := float64(i+j+1) / 2
+		ties := float64(j - i)
+		tieSum += ties*ties*ties - ties
+
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankSumX += rank
+			}
+		}
+
+		i = j
+	}
+
+	u1 := rankSumX - float64(n1*(n1+1))/2
+	u := math.Min(u1, float64(n1*n2)-u1)
+
+	if tieSum == 0 && n <= exactMannWhitneyLimit {
+		counts := uCounts(n1, n2)
+
+		var below, total float64
+
+		for k, c := range counts {
+			if float64(k) <= u {
+				below += c
+			}
+			total += c
+		}
+
+		return math.Min(1, 2*below/total)
+	}
+
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * (float64(n+1) - tieSum/float64(n*(n-1))))
+
+	if sigma == 0 {
+		return 1
+	}
+
+	// continuity correction, u <= mu
+	z := (u - mu + 0.5) / sigma
+
+	return math.Min(1, math.Erfc(-z/math.Sqrt2))
+}
+
+// uCounts returns how many orderings of n1 and n2 distinct values result in U = 0, 1, ..., n1*n2
+func uCounts(n1, n2 int) []float64 {
+	f := make([][][]float64, n1+1)
+
+	for m := 0; m <= n1; m++ {
+		f[m] = make([][]float64, n2+1)
+
+		for n := 0; n <= n2; n++ {
+			f[m][n] = make([]float64, m*n+1)
+
+			if m == 0 || n == 0 {
+				f[m][n][0] = 1
+				continue
+			}
+
+			for u := 0; u <= m*n; u++ {
+				// the largest value either belongs to the first sample, adding n to U, or to the second one
+				if u >= n {
+					f[m][n][u] += f[m-1][n][u-n]
+				}
+
+				if u <= m*(n-1) {
+					f[m][n][u] += f[m][n-1][u]
+				}
+			}
+		}
+	}
+
+	return f[n1][n2]
+}
+
+// observation is a sample value along with the sample it belongs to
+type observation struct {
+	value float64
+	fromX bool
+}
+
+type sortByValue []observation
+
+func (a sortByValue) Len() int           { return len(a) }
+func (a sortByValue) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a sortByValue) Less(i, j int) bool { return a[i].value < a[j].value }
@@ -0,0 +1,68 @@
+package prettybenchmarks
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_mannWhitneyU(t *testing.T) {
+	for _, tt := range []struct {
+		x, y     []float64
+		expected float64
+	}{
+		{[]float64{1, 2, 3}, []float64{4, 5, 6}, 0.1},
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 2.0 / 252},
+		{[]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 2.0 / 252},
+		{[]float64{1, 3, 5}, []float64{2, 4, 6}, 0.7},
+		{[]float64{2, 2, 2}, []float64{2, 2, 2}, 1},
+		{nil, []float64{1}, 1},
+		// ties use the normal approximation
+		{[]float64{1, 1, 2, 2, 3, 3}, []float64{4, 4, 5, 5, 6, 6}, 0.0046},
+	} {
+		if actual := mannWhitneyU(tt.x, tt.y); math.Abs(actual-tt.expected) > 1e-4 {
+			t.Errorf("Testing %v against %v: expected p %v, actual %v", tt.x, tt.y, tt.expected, actual)
+		}
+	}
+}
+
+func Test_aggregateMissingValues(t *testing.T) {
+	defer setOutlierMode(outliersOff)
+
+	samples := (*newResults([][]byte{
+		[]byte("BenchmarkFoo-8\t100\t1000 ns/op\t16 B/op\tx allocs/op"),
+		[]byte("BenchmarkFoo-8\t100\tbad ns/op\t16 B/op\ty allocs/op"),
+		[]byte("BenchmarkFoo-8\t100\t1200 ns/op\t16 B/op\tz allocs/op"),
+	}))["Foo"]
+
+	for _, tt := range []struct {
+		mode  string
+		speed float64
+	}{
+		{outliersOff, 1100},
+		{outliersMedian, 1100},
+		{outliersNone, 1100},
+		{outliersIQR, 1100},
+		{"trim:40", 1100},
+	} {
+		if err := setOutlierMode(tt.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		agg := aggregate(samples)
+
+		if agg.Speed != tt.speed || agg.Bps != 16 || agg.Aps != -1 {
+			t.Errorf("Aggregating malformed samples with outlier mode %q: expected %v ns/op, 16 B/op, -1 allocs/op, actual %v, %v, %v",
+				tt.mode, tt.speed, agg.Speed, agg.Bps, agg.Aps)
+		}
+	}
+
+	bm := &benchmark{results: &results{"Foo": {aggregate(samples)}}, info: &benchmarkInfo{suggestedTiming: "ns", benchmemUsed: true}}
+
+	if actual := findColumn(baseColumns, "allocs").format(bm, (*bm.results)["Foo"][0], true); actual != unknownValue {
+		t.Errorf("Rendering allocs/op missing in every sample: expected %q, actual %q", unknownValue, actual)
+	}
+
+	if actual := values(samples, speed); len(actual) != 2 {
+		t.Errorf("Collecting known values for the U-test: expected 2, actual %v", actual)
+	}
+}
@@ -0,0 +1,206 @@
+package prettybenchmarks
+
+import (
+	"regexp"
+	"strings"
+)
+
+const failedMarker = "✗"
+
+var (
+	// regExPackage matches the line go test prints per package, e.g. "ok  pkg 1.2s" or "FAIL pkg [build failed]"
+	regExPackage = regexp.MustCompile(`^(ok|FAIL|\?)\s+(\S+)\s+(.+)$`)
+	// regExBenchmarkOutcome matches the line go test prints for failed and skipped benchmarks
+	regExBenchmarkOutcome = regexp.MustCompile(`^--- (FAIL|SKIP): (\S+)`)
+	// regExBuildError matches compiler output: the package header and errors with their position
+	regExBuildError = regexp.MustCompile(`^(# \S+|\S+\.go:\d+(:\d+)?: .*)$`)
+	regExExitStatus = regexp.MustCompile(`^exit status \d+$`)
+	regExIterSuffix = regexp.MustCompile(`_\d+$`)
+	regExPanic      = regexp.MustCompile(`^panic: `)
+)
+
+// summary holds the lines go test printed besides benchmark results, classified by their kind
+type summary struct {
+	config      []string
+	buildErrors []string
+	failures    []string
+	skips       []string
+	panics      [][]string
+	packages    [][]string
+	status      []string
+	other       []string
+}
+
+// newSummary classifies lines in the order go test printed them. A panic spans all lines up to the
+// next package or status line
+func newSummary(lines []string) *summary {
+	s := &summary{}
+	inPanic := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if inPanic {
+			if m := regExPackage.FindStringSubmatch(trimmed); m == nil && !isStatusLine(trimmed) {
+				s.panics[len(s.panics)-1] = append(s.panics[len(s.panics)-1], strings.TrimRight(line, "\r\n"))
+				continue
+			}
+
+			inPanic = false
+		}
+
+		switch m := regExBenchmarkOutcome.FindStringSubmatch(trimmed); {
+		case trimmed == "":
+		case regExPanic.MatchString(trimmed):
+			s.panics = append(s.panics, []string{trimmed})
+			inPanic = true
+		case m != nil && m[1] == "FAIL":
+			if !StringsContains(s.failures, normalizeName(m[2])) {
+				s.failures = append(s.failures, normalizeName(m[2]))
+			}
+		case m != nil:
+			if !StringsContains(s.skips, normalizeName(m[2])) {
+				s.skips = append(s.skips, normalizeName(m[2]))
+			}
+		case isStatusLine(trimmed):
+			s.status = append(s.status, trimmed)
+		case regExPackage.MatchString(trimmed):
+			s.packages = append(s.packages, regExPackage.FindStringSubmatch(trimmed)[1:])
+		case regExBuildError.MatchString(trimmed):
+			s.buildErrors = append(s.buildErrors, trimmed)
+		case regExConfig.MatchString(trimmed):
+			s.config = append(s.config, trimmed)
+		default:
+			s.other = append(s.other, trimmed)
+		}
+	}
+
+	return s
+}
+
+func isStatusLine(line string) bool {
+	return line == linePassed || line == lineSkipped || line == lineFail || regExExitStatus.MatchString(line)
+}
+
+// String renders the summary section by section, the output of failed and skipped benchmarks is shown
+// along with them, the one of all other benchmarks below
+func (s *summary) String() string {
+	var (
+		out   []string
+		shown = make(map[string]bool)
+	)
+
+	out = append(out, s.config...)
+	out = append(out, s.other...)
+
+	for _, line := range s.buildErrors {
+		out = append(out, red(line))
+	}
+
+	for _, name := range s.failures {
+		out = append(out, red(bold("--- FAIL"))+" "+bold(name))
+
+		for _, line := range logsOf(name) {
+			out = append(out, "  "+line)
+		}
+
+		shown[name] = true
+	}
+
+	for _, name := range s.skips {
+		skip := gray(bold("--- SKIP")) + " " + bold(name)
+
+		if reason := logsOf(name); len(reason) > 0 {
+			skip += ": " + strings.Join(reason, " ")
+		}
+
+		out = append(out, skip)
+		shown[name] = true
+	}
+
+	for _, l := range benchmarkLogs {
+		if shown[l.name] {
+			continue
+		}
+
+		out = append(out, bold(l.name)+":")
+
+		for _, line := range l.lines {
+			out = append(out, "  "+gray(line))
+		}
+	}
+
+	for _, p := range benchmarkPanics {
+		out = append(out, newPanicTrace(p.name, p.lines).lines()...)
+	}
+
+	for _, trace := range s.panics {
+		out = append(out, newPanicTrace("", trace).lines()...)
+	}
+
+	for _, line := range s.status {
+		switch line {
+		case linePassed:
+			out = append(out, green(bold(line)))
+		case lineSkipped:
+			out = append(out, gray(bold(line)))
+		default:
+			out = append(out, red(bold(line)))
+		}
+	}
+
+	for _, p := range s.packages {
+		status := p[0]
+
+		switch status {
+		case "ok":
+			status = green(bold(status))
+		case "FAIL":
+			status = red(bold(status))
+		default:
+			status = gray(bold(status))
+		}
+
+		out = append(out, status+" "+p[1]+" "+gray(p[2]))
+	}
+
+	if len(out) == 0 {
+		return ""
+	}
+
+	return strings.Join(out, "\n") + "\n"
+}
+
+// logsOf returns the output of the benchmark with the given (normalized) name
+func logsOf(name string) []string {
+	for _, l := range benchmarkLogs {
+		if l.name == name {
+			return l.lines
+		}
+	}
+
+	return nil
+}
+
+// hasFailed reports whether go test reported the benchmark with the given name as failed or it panicked, with any iterations
+func hasFailed(name string) bool {
+	if hasPanicked(name) {
+		return true
+	}
+
+	for _, line := range unparsableLines {
+		m := regExBenchmarkOutcome.FindStringSubmatch(strings.TrimSpace(line))
+
+		if m == nil || m[1] != "FAIL" {
+			continue
+		}
+
+		failed := normalizeName(m[2])
+
+		if failed == name || regExIterSuffix.ReplaceAllString(failed, "") == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testSummaryLines = []string{
+	"goos: linux\n",
+	"pkg: example.com/foo\n",
+	"--- FAIL: BenchmarkBroken-8\n",
+	"--- FAIL: BenchmarkBroken-8\n",
+	"--- SKIP: BenchmarkSkipped\n",
+	"something else\n",
+	"# example.com/bar\n",
+	"bar/bar.go:12:3: undefined: baz\n",
+	"panic: boom\n",
+	"\n",
+	"goroutine 7 [running]:\n",
+	"example.com/foo.BenchmarkPanic(0xc000)\n",
+	"\t/src/foo/foo_test.go:12 +0x1d\n",
+	"exit status 2\n",
+	"FAIL\n",
+	"FAIL\texample.com/foo\t0.512s\n",
+	"ok  \texample.com/baz\t11.2s\n",
+	"FAIL\texample.com/bar [build failed]\n",
+	"?   \texample.com/qux\t[no test files]\n",
+}
+
+func Test_newSummary(t *testing.T) {
+	s := newSummary(testSummaryLines)
+
+	expected := &summary{
+		config:      []string{"goos: linux", "pkg: example.com/foo"},
+		buildErrors: []string{"# example.com/bar", "bar/bar.go:12:3: undefined: baz"},
+		failures:    []string{"Broken"},
+		skips:       []string{"Skipped"},
+		panics: [][]string{{
+			"panic: boom",
+			"",
+			"goroutine 7 [running]:",
+			"example.com/foo.BenchmarkPanic(0xc000)",
+			"\t/src/foo/foo_test.go:12 +0x1d",
+		}},
+		packages: [][]string{
+			{"FAIL", "example.com/foo", "0.512s"},
+			{"ok", "example.com/baz", "11.2s"},
+			{"FAIL", "example.com/bar", "[build failed]"},
+			{"?", "example.com/qux", "[no test files]"},
+		},
+		status: []string{"exit status 2", "FAIL"},
+		other:  []string{"something else"},
+	}
+
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("Classifying lines: expected %#v, actual %#v", expected, s)
+	}
+}
+
+func Test_summaryString(t *testing.T) {
+	defer func() {
+		colorEnabled = true
+		benchmarkLogs = nil
+	}()
+
+	colorEnabled = false
+	benchmarkLogs = []*benchmarkLog{
+		{"Broken", []string{"broken_test.go:8: boom"}},
+		{"Skipped", []string{"skipped_test.go:5: needs network"}},
+		{"Chatty", []string{"chatty_test.go:3: hello"}},
+	}
+
+	s := newSummary([]string{
+		"--- FAIL: BenchmarkBroken-8\n",
+		"--- SKIP: BenchmarkSkipped\n",
+		"PASS\n",
+		"ok  \texample.com/baz\t11.2s\n",
+	})
+
+	expected := "--- FAIL Broken\n" +
+		"  broken_test.go:8: boom\n" +
+		"--- SKIP Skipped: skipped_test.go:5: needs network\n" +
+		"Chatty:\n" +
+		"  chatty_test.go:3: hello\n" +
+		"PASS\n" +
+		"ok example.com/baz 11.2s\n"
+
+	if actual := s.String(); actual != expected {
+		t.Errorf("Rendering summary: expected %q, actual %q", expected, actual)
+	}
+}
+
+func Test_hasFailed(t *testing.T) {
+	defer func() { unparsableLines = nil }()
+
+	unparsableLines = []string{"--- FAIL: BenchmarkBroken_100-8", "--- SKIP: BenchmarkSkipped"}
+
+	for name, expected := range map[string]bool{"Broken": true, "Broken_100": true, "Skipped": false, "Fine": false} {
+		if actual := hasFailed(name); actual != expected {
+			t.Errorf("Checking if %s failed: expected %v, actual %v", name, expected, actual)
+		}
+	}
+}
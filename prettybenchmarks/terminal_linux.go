@@ -0,0 +1,5 @@
+package prettybenchmarks
+
+import "syscall"
+
+const ioctlReadTermios = syscall.TCGETS
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package prettybenchmarks
+
+import "os"
+
+// isTerminal reports whether f is a character device, which is the best guess without terminal ioctls
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,20 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package prettybenchmarks
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal reports whether f is a terminal, i.e. reading its terminal attributes succeeds. Unlike checking
+// for a character device this is false for /dev/null
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_IOCTL, f.Fd(), ioctlReadTermios, uintptr(unsafe.Pointer(&termios)), 0, 0, 0)
+
+	return errno == 0
+}
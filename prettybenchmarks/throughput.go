@@ -0,0 +1,133 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bytesRule assigns the number of bytes processed per operation to the benchmarks whose name matches re
+type bytesRule struct {
+	re    *regexp.Regexp
+	bytes float64
+}
+
+// bytesRules derive the throughput of benchmarks which don't call b.SetBytes, see setBytesPerOp
+var bytesRules []*bytesRule
+
+// throughputMode adds the operations per second derived from ns/op to the table
+var throughputMode bool
+
+// regExBytesRuleEnd matches the =bytes ending a bytes per op rule, followed by the comma separating it from
+// the next rule. Commas within regexes (e.g. \d{1,4}) don't follow =bytes and are kept
+var regExBytesRuleEnd = regexp.MustCompile(`=\s*([^,=]*?)\s*(?:,|$)`)
+
+// setBytesPerOp parses the --bytes-per-op flag, a comma separated list of regex=bytes rules. The first rule
+// whose regex matches a benchmark's name (without the Benchmark prefix and the procs suffix, e.g. Encode_1024)
+// defines the bytes it processes per operation
+func setBytesPerOp(s string) error {
+	bytesRules = nil
+
+	for rest := s; strings.TrimSpace(rest) != ""; {
+		m := regExBytesRuleEnd.FindStringSubmatchIndex(rest)
+
+		if m == nil {
+			return fmt.Errorf("invalid bytes per op rule %q, use regex=bytes", strings.TrimSpace(rest))
+		}
+
+		rule := strings.TrimSpace(rest[:m[3]])
+		expr, bytes := strings.TrimSpace(rest[:m[0]]), rest[m[2]:m[3]]
+		rest = rest[m[1]:]
+
+		if expr == "" {
+			return fmt.Errorf("invalid bytes per op rule %q, use regex=bytes", rule)
+		}
+
+		re, err := regexp.Compile(expr)
+
+		if err != nil {
+			return fmt.Errorf("invalid bytes per op rule %q: %s", rule, err)
+		}
+
+		b, err := strconv.ParseFloat(bytes, 64)
+
+		if err != nil || b <= 0 {
+			return fmt.Errorf("invalid bytes per op rule %q, bytes must be a positive number", rule)
+		}
+
+		bytesRules = append(bytesRules, &bytesRule{re, b})
+	}
+
+	return nil
+}
+
+// mappedBytesPerOp returns the bytes r processes per operation according to the --bytes-per-op rules, 0 if none matches
+func mappedBytesPerOp(r *result) float64 {
+	name := displayName(r.Name, r.FnIterations, 1)
+
+	for _, rule := range bytesRules {
+		if rule.re.MatchString(name) {
+			return rule.bytes
+		}
+	}
+
+	return 0
+}
+
+// bytesPerSecond returns the throughput of r reported via b.SetBytes or else derived from its ns/op
+// and the --bytes-per-op rules, -1 if neither is known
+func bytesPerSecond(r *result) float64 {
+	if v, ok := r.Metrics["MB/s"]; ok {
+		return v * 1e6
+	}
+
+	if b := mappedBytesPerOp(r); b > 0 && r.Speed > 0 {
+		return b * 1e9 / r.Speed
+	}
+
+	return -1
+}
+
+// opsPerSecond returns the operations per second derived from r's ns/op, -1 if unknown
+func opsPerSecond(r *result) float64 {
+	if r.Speed <= 0 {
+		return -1
+	}
+
+	return 1e9 / r.Speed
+}
+
+// hasDerivedThroughput reports whether the throughput of any benchmark of bm is derived from the --bytes-per-op rules
+func hasDerivedThroughput(bm *benchmark) bool {
+	for _, r := range allResults(bm) {
+		if _, ok := r.Metrics["MB/s"]; !ok && mappedBytesPerOp(r) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func renderOps(bm *benchmark, r *result, v float64) string {
+	return renderMetric(fmtFloatUnit, v, sampleValues(bm, r, opsPerSecond))
+}
+
+var opsColumn = &column{
+	name:   "ops/s",
+	header: staticHeader("ops/s"),
+	align:  alignRight,
+	format: func(bm *benchmark, r *result, first bool) string {
+		v := opsPerSecond(r)
+
+		if v < 0 {
+			return ""
+		}
+		return renderOps(bm, r, v)
+	},
+	render:    renderOps,
+	visible:   func(bm *benchmark) bool { return throughputMode },
+	perInput:  true,
+	value:     opsPerSecond,
+	direction: higherIsBetter,
+}
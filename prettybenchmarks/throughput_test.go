@@ -0,0 +1,87 @@
+package prettybenchmarks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_setBytesPerOp(t *testing.T) {
+	defer setBytesPerOp("")
+
+	for _, tt := range []struct {
+		s     string
+		rules int
+		err   bool
+	}{
+		{"", 0, false},
+		{"Encode_1024=1024", 1, false},
+		{"^Decode$=4096, (?i)hash=64", 2, false},
+		{"a=b=8", 1, false},
+		{`Encode_\d{1,4}=1024`, 1, false},
+		{`Encode_\d{1,4}=1024, Hash_(1|2){2,}=64`, 2, false},
+		{"Encode=1024,", 1, false},
+		{"Encode=abc,Hash=64", 0, true},
+		{"Encode", 0, true},
+		{"=1024", 0, true},
+		{"Encode=-1", 0, true},
+		{"([=1", 0, true},
+	} {
+		err := setBytesPerOp(tt.s)
+
+		if (err != nil) != tt.err {
+			t.Errorf("Parsing bytes per op %q: expected error %v, got %v", tt.s, tt.err, err)
+			continue
+		}
+
+		if err == nil && len(bytesRules) != tt.rules {
+			t.Errorf("Parsing bytes per op %q: expected %d rules, actual %d", tt.s, tt.rules, len(bytesRules))
+		}
+	}
+}
+
+func Test_throughput(t *testing.T) {
+	defer setBytesPerOp("")
+	defer func() {
+		colorEnabled = true
+		throughputMode = false
+	}()
+
+	colorEnabled = false
+	throughputMode = true
+
+	if err := setBytesPerOp("^Hash_=64,Encode=1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Hash_10-8 	 100	 500 ns/op\n"),
+		[]byte("Benchmark_Encode-8 	 100	 2000 ns/op	 250.00 MB/s\n"),
+		[]byte("Benchmark_Decode-8 	 100	 4000 ns/op\n"),
+	})
+
+	cols, err := selectColumns(bm, "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"name", "iterations", "runs", "time", "element", "ops/s", "MB/s"}; !reflect.DeepEqual(columnNames(cols), expected) {
+		t.Fatalf("Selecting throughput columns: expected %v, actual %v", expected, columnNames(cols))
+	}
+
+	for _, tt := range []struct {
+		name string
+		ops  string
+		mbps string
+	}{
+		{"Hash", "2,000,000.00", "128.000"},
+		{"Encode", "500,000.00", "250.000"},
+		{"Decode", "250,000.00", ""},
+	} {
+		r := (*bm.results)[tt.name][0]
+
+		if ops, mbps := cols[5].format(bm, r, true), cols[6].format(bm, r, true); ops != tt.ops || mbps != tt.mbps {
+			t.Errorf("Deriving throughput of %s: expected %q ops/s and %q MB/s, actual %q and %q", tt.name, tt.ops, tt.mbps, ops, mbps)
+		}
+	}
+}
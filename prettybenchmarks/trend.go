@@ -0,0 +1,324 @@
+package prettybenchmarks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/apcera/termtables"
+)
+
+const (
+	// trendAlpha is the significance level a change point has to reach to be reported
+	trendAlpha = 0.05
+	// trendPermutations is the number of random permutations used to estimate the p-value of a change point
+	trendPermutations = 999
+	// trendSeed makes the permutation test reproducible
+	trendSeed = 1
+)
+
+// changePoint is a step change within the recorded history of a benchmark metric
+type changePoint struct {
+	Benchmark    string    `json:"benchmark"`
+	FnIterations int       `json:"iterations"`
+	Procs        int       `json:"procs"`
+	Metric       string    `json:"metric"`
+	Commit       string    `json:"commit,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	Before       float64   `json:"before"`
+	After        float64   `json:"after"`
+	Change       float64   `json:"change"`
+	P            float64   `json:"p"`
+}
+
+var trendMetrics = []struct {
+	unit  string
+	value func(r *result) float64
+}{
+	{"ns/op", speed},
+	{"B/op", bytesPerOp},
+	{"allocs/op", allocsPerOp},
+}
+
+// trendCommand implements 'pb trend [benchmark]': it searches the history of every (or the given)
+// benchmark for statistically significant step changes and reports the commits introducing them
+func trendCommand(args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: pb trend [benchmark]")
+	}
+
+	records, err := readHistory(*historyFlag)
+
+	if err != nil {
+		return err
+	}
+
+	var points []*changePoint
+
+	for _, s := range historySeries(records, args) {
+		points = append(points, s.changePoints()...)
+	}
+
+	if outputFormat == formatJSON {
+		if points == nil {
+			points = []*changePoint{}
+		}
+		return printJSON(points)
+	}
+
+	if len(points) == 0 {
+		fmt.Println("no significant changes found")
+		return nil
+	}
+
+	printChangePoints(points)
+
+	return nil
+}
+
+// series is the history of one metric of one benchmark, one median value per recorded run
+type series struct {
+	key     resultKey
+	metric  string
+	values  []float64
+	records []*historyRecord
+}
+
+// historySeries builds one series per benchmark and metric found in the records,
+// restricted to the benchmark named in args if any
+func historySeries(records []*historyRecord, args []string) []*series {
+	var (
+		all    []*series
+		name   string
+		byKey  = make(map[resultKey][]*series)
+		sorted []resultKey
+	)
+
+	if len(args) == 1 {
+		name = normalizeName(args[0])
+	}
+
+	for _, record := range records {
+		keys, samples := groupSamples(record.Results, name)
+
+		for _, k := range keys {
+			if _, ok := byKey[k]; !ok {
+				sorted = append(sorted, k)
+
+				for _, m := range trendMetrics {
+					byKey[k] = append(byKey[k], &series{key: k, metric: m.unit})
+				}
+			}
+
+			// runs missing a metric (e.g. recorded without -benchmem) are left out of its series
+			for i, m := range trendMetrics {
+				v := medianOf(samples[k], m.value)
+
+				if v < 0 {
+					continue
+				}
+
+				s := byKey[k][i]
+				s.values = append(s.values, v)
+				s.records = append(s.records, record)
+			}
+		}
+	}
+
+	sort.Sort(sortByKey(sorted))
+
+	for _, k := range sorted {
+		for _, s := range byKey[k] {
+			if len(s.values) > 0 {
+				all = append(all, s)
+			}
+		}
+	}
+
+	return all
+}
+
+type sortByKey []resultKey
+
+func (k sortByKey) Len() int      { return len(k) }
+func (k sortByKey) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k sortByKey) Less(i, j int) bool {
+	switch {
+	case k[i].name != k[j].name:
+		return k[i].name < k[j].name
+	case k[i].fnIterations != k[j].fnIterations:
+		return k[i].fnIterations < k[j].fnIterations
+	}
+	return k[i].procs < k[j].procs
+}
+
+// changePoints runs binary segmentation on the series: the split maximizing the CUSUM statistic is
+// reported if a permutation test deems it significant, then both halves are searched recursively
+func (s *series) changePoints() []*changePoint {
+	var points []*changePoint
+
+	rnd := rand.New(rand.NewSource(trendSeed))
+
+	var segment func(from, to int)
+	segment = func(from, to int) {
+		values := s.values[from:to]
+		k, stat := bestSplit(values)
+
+		if k < 0 || stat == 0 {
+			return
+		}
+
+		p := permutationP(values, stat, rnd)
+
+		if p >= trendAlpha {
+			return
+		}
+
+		before, after := mean(values[:k]), mean(values[k:])
+		record := s.records[from+k]
+
+		points = append(points, &changePoint{
+			Benchmark:    s.key.name,
+			FnIterations: s.key.fnIterations,
+			Procs:        s.key.procs,
+			Metric:       s.metric,
+			Commit:       record.Commit,
+			Timestamp:    record.Timestamp,
+			Before:       before,
+			After:        after,
+			Change:       relativeChange(before, after),
+			P:            p,
+		})
+
+		segment(from, from+k)
+		segment(from+k, to)
+	}
+
+	segment(0, len(s.values))
+
+	sort.Sort(sortByTimestampPoints(points))
+
+	return points
+}
+
+// bestSplit returns the index splitting values into the two segments whose means differ the most,
+// weighted by the segment sizes, and the value of that statistic. -1 if values can't be split
+func bestSplit(values []float64) (int, float64) {
+	n := len(values)
+
+	if n < 2 {
+		return -1, 0
+	}
+
+	var (
+		best     = -1
+		bestStat float64
+		total    float64
+		prefix   float64
+	)
+
+	for _, v := range values {
+		total += v
+	}
+
+	for k := 1; k < n; k++ {
+		prefix += values[k-1]
+		before := prefix / float64(k)
+		after := (total - prefix) / float64(n-k)
+		stat := math.Sqrt(float64(k*(n-k))/float64(n)) * math.Abs(before-after)
+
+		if stat > bestStat {
+			best, bestStat = k, stat
+		}
+	}
+
+	return best, bestStat
+}
+
+// permutationP estimates the probability of finding a split at least as strong as stat in a random order of values
+func permutationP(values []float64, stat float64, rnd *rand.Rand) float64 {
+	shuffled := make([]float64, len(values))
+	copy(shuffled, values)
+
+	exceeding := 0
+
+	for i := 0; i < trendPermutations; i++ {
+		for j := len(shuffled) - 1; j > 0; j-- {
+			k := rnd.Intn(j + 1)
+			shuffled[j], shuffled[k] = shuffled[k], shuffled[j]
+		}
+
+		if _, s := bestSplit(shuffled); s >= stat {
+			exceeding++
+		}
+	}
+
+	return float64(exceeding+1) / float64(trendPermutations+1)
+}
+
+type sortByTimestampPoints []*changePoint
+
+func (p sortByTimestampPoints) Len() int           { return len(p) }
+func (p sortByTimestampPoints) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p sortByTimestampPoints) Less(i, j int) bool { return p[i].Timestamp.Before(p[j].Timestamp) }
+
+func printChangePoints(points []*changePoint) {
+	table = termtables.CreateTable()
+	table.Style.Alignment = termtables.AlignRight
+	table.AddHeaders(bold("Benchmark"), bold("Metric"), bold("Run"), bold("Before"), bold("After"), bold("Change"), bold("p"))
+
+	for _, p := range points {
+		record := &historyRecord{Timestamp: p.Timestamp, Commit: p.Commit}
+
+		table.AddRow(
+			bold(displayName(p.Benchmark, p.FnIterations, p.Procs)),
+			p.Metric,
+			record.label(),
+			renderMetric(fmtFloat, p.Before, nil),
+			renderMetric(fmtFloat, p.After, nil),
+			formatDelta(p.Before, p.After, lowerIsBetter),
+			RenderFloat(fmtFloat, p.P),
+		)
+	}
+
+	table.SetAlign(termtables.AlignLeft, 1)
+	table.SetAlign(termtables.AlignLeft, 2)
+	table.SetAlign(termtables.AlignLeft, 3)
+
+	fmt.Println(table.Render())
+}
+
+// displayName renders a benchmark key the way go test prints it, without the Benchmark prefix
+func displayName(name string, fnIterations, procs int) string {
+	if fnIterations > -1 {
+		name = fmt.Sprintf("%s_%d", name, fnIterations)
+	}
+
+	if procs > 1 {
+		name = fmt.Sprintf("%s-%d", name, procs)
+	}
+
+	return name
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+
+	for _, v := range values {
+		sum += v
+	}
+
+	return sum / float64(len(values))
+}
+
+// relativeChange returns the change from old to new in percent
+func relativeChange(old, new float64) float64 {
+	if old == 0 {
+		return 0
+	}
+
+	return (new - old) / old * 100
+}
@@ -0,0 +1,124 @@
+package prettybenchmarks
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_bestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		values []float64
+		split  int
+	}{
+		{[]float64{10, 10, 10, 20, 20}, 3},
+		{[]float64{10, 20}, 1},
+		{[]float64{10}, -1},
+		{[]float64{10, 10, 10}, -1},
+	} {
+		if actual, _ := bestSplit(tt.values); actual != tt.split {
+			t.Errorf("Splitting %v: expected %d, actual %d", tt.values, tt.split, actual)
+		}
+	}
+}
+
+func testSeries(values []float64) *series {
+	s := &series{key: resultKey{"Foo", -1, 8}, metric: "ns/op", values: values}
+	start := time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range values {
+		s.records = append(s.records, &historyRecord{Timestamp: start.Add(time.Duration(i) * time.Hour), Commit: string(rune('a' + i))})
+	}
+
+	return s
+}
+
+func Test_changePoints(t *testing.T) {
+	step := testSeries([]float64{100, 101, 99, 100, 102, 100, 120, 121, 119, 120, 122, 121})
+	points := step.changePoints()
+
+	if len(points) != 1 || points[0].Commit != "g" || points[0].P >= trendAlpha || points[0].Change < 19 || points[0].Change > 21 {
+		t.Errorf("Detecting change points in %v: expected one at commit g, actual %#v", step.values, points)
+	}
+
+	twoSteps := testSeries([]float64{100, 101, 99, 100, 101, 120, 121, 119, 120, 121, 140, 141, 139, 140, 141})
+
+	var commits []string
+
+	for _, p := range twoSteps.changePoints() {
+		commits = append(commits, p.Commit)
+	}
+
+	if len(commits) != 2 || commits[0] != "f" || commits[1] != "k" {
+		t.Errorf("Detecting change points in %v: expected commits f and k, actual %v", twoSteps.values, commits)
+	}
+
+	noise := testSeries([]float64{100, 103, 98, 101, 99, 102, 100, 97, 101, 100})
+
+	if points := noise.changePoints(); len(points) != 0 {
+		t.Errorf("Detecting change points in %v: expected none, actual %#v", noise.values, points)
+	}
+}
+
+func Test_historySeries(t *testing.T) {
+	records := []*historyRecord{
+		newHistoryRecord(testHistoryLines, time.Date(2015, 11, 1, 12, 0, 0, 0, time.UTC)),
+		newHistoryRecord([][]byte{[]byte("Benchmark_Foo_10-8 	 100	 990 ns/op\n")}, time.Date(2015, 11, 2, 12, 0, 0, 0, time.UTC)),
+	}
+
+	// without -benchmem only the ns/op series are built
+	all := historySeries(records, nil)
+
+	if len(all) != 2 || all[0].key.name != "Bar" || len(all[1].values) != 2 {
+		t.Errorf("Building series of all benchmarks: unexpected %#v", all)
+	}
+
+	foo := historySeries(records, []string{"Foo_10"})
+
+	if len(foo) != 1 || foo[0].values[0] != 1100 || foo[0].values[1] != 990 {
+		t.Errorf("Building series of Foo_10: unexpected %#v", foo)
+	}
+}
+
+func Test_historySeriesMissingMetric(t *testing.T) {
+	var records []*historyRecord
+
+	for i := 0; i < 10; i++ {
+		line := "Benchmark_Foo-8 	 100	 1000 ns/op\n"
+
+		// -benchmem is turned on partway
+		if i >= 5 {
+			line = "Benchmark_Foo-8 	 100	 1000 ns/op	 64 B/op	 2 allocs/op\n"
+		}
+
+		records = append(records, newHistoryRecord([][]byte{[]byte(line)}, time.Date(2015, 11, i+1, 12, 0, 0, 0, time.UTC)))
+	}
+
+	all := historySeries(records, nil)
+
+	if len(all) != len(trendMetrics) {
+		t.Fatalf("Building series: expected %d, actual %d", len(trendMetrics), len(all))
+	}
+
+	for _, s := range all {
+		expected := 10
+
+		if s.metric != "ns/op" {
+			expected = 5
+		}
+
+		for _, v := range s.values {
+			if v < 0 {
+				t.Errorf("Building %s series: missing values included %v", s.metric, s.values)
+				break
+			}
+		}
+
+		if len(s.values) != expected || len(s.records) != expected {
+			t.Errorf("Building %s series: expected %d runs, actual %d", s.metric, expected, len(s.values))
+		}
+
+		if points := s.changePoints(); len(points) != 0 {
+			t.Errorf("Detecting change points in %s: expected none, actual %#v", s.metric, points)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package prettybenchmarks
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	unitsGlobal = "global"
+	unitsGroup  = "group"
+	unitsCell   = "cell"
+
+	bytesRaw = "raw"
+	bytesIEC = "iec"
+	bytesSI  = "si"
+)
+
+// unitMode defines whether one unit is used for the whole table, one per benchmark group or one per value
+var unitMode = unitsGlobal
+
+// byteMode defines if memory values are shown as raw byte counts or scaled to binary (IEC) or decimal (SI) units
+var byteMode = bytesRaw
+
+var byteUnits = map[string][]string{
+	bytesIEC: {"B", "KiB", "MiB", "GiB", "TiB"},
+	bytesSI:  {"B", "kB", "MB", "GB", "TB"},
+}
+
+var byteBases = map[string]float64{
+	bytesIEC: 1024,
+	bytesSI:  1000,
+}
+
+var timeDivisors = map[string]float64{
+	"ns": 1,
+	"µs": 1e3,
+	"ms": 1e6,
+	"s":  1e9,
+}
+
+func setUnitMode(mode string) error {
+	switch mode {
+	case unitsGlobal, unitsGroup, unitsCell:
+		unitMode = mode
+	case "":
+		unitMode = unitsGlobal
+	default:
+		return fmt.Errorf("invalid unit mode %q, use %s, %s or %s", mode, unitsGlobal, unitsGroup, unitsCell)
+	}
+
+	// an explicitly provided time interval applies to all benchmarks
+	if timing != "" {
+		unitMode = unitsGlobal
+	}
+
+	return nil
+}
+
+func setByteMode(mode string) error {
+	switch mode {
+	case bytesRaw, bytesIEC, bytesSI:
+		byteMode = mode
+	case "":
+		byteMode = bytesRaw
+	default:
+		return fmt.Errorf("invalid byte mode %q, use %s, %s or %s", mode, bytesRaw, bytesIEC, bytesSI)
+	}
+
+	return nil
+}
+
+// suitableTiming returns the largest time unit in which ns is still at least 1
+func suitableTiming(ns float64) string {
+	switch {
+	case ns <= 1e3:
+		return "ns"
+	case ns > 1e3 && ns <= 1e6:
+		return "µs"
+	case ns > 1e6 && ns <= 1e9:
+		return "ms"
+	default:
+		return "s"
+	}
+}
+
+// groupTiming returns the suitable time unit for the slowest benchmark within the named group
+func groupTiming(bm *benchmark, name string) string {
+	var slowest float64
+
+	for _, r := range (*bm.results)[name] {
+		if slowest < r.Speed {
+			slowest = r.Speed
+		}
+	}
+
+	return suitableTiming(slowest)
+}
+
+func formatTime(bm *benchmark, r *result, first bool) string {
+	if r.Speed < 0 {
+		return unknownValue
+	}
+
+	return renderTime(bm, r, r.Speed)
+}
+
+// renderTime renders the time v in ns in the row of r, in a unit chosen for the whole table, r's group
+// or v itself, depending on unitMode
+func renderTime(bm *benchmark, r *result, v float64) string {
+	samples := sampleValues(bm, r, speed)
+
+	switch unitMode {
+	case unitsGroup:
+		unit := groupTiming(bm, r.Name)
+		return renderMetric(fmtTimeUnit, v/timeDivisors[unit], samples) + " " + unit
+	case unitsCell:
+		unit := suitableTiming(v)
+		return renderMetric(fmtTimeUnit, v/timeDivisors[unit], samples) + " " + unit
+	}
+
+	if bm.info.suggestedTiming == "ns" {
+		return renderMetric(fmtFloatNS, v, samples)
+	}
+	return renderMetric(fmtTime, v/timeDivisors[bm.info.suggestedTiming], samples)
+}
+
+func speed(r *result) float64 {
+	return r.Speed
+}
+
+// suitableByteUnit returns the largest byte unit of the current byteMode in which b is still at least 1
+// together with the divisor to convert bytes into that unit
+func suitableByteUnit(b float64) (string, float64) {
+	units := byteUnits[byteMode]
+	base := byteBases[byteMode]
+	divisor := float64(1)
+	i := 0
+
+	for ; i < len(units)-1 && b >= divisor*base; i++ {
+		divisor *= base
+	}
+
+	return units[i], divisor
+}
+
+// byteHeader returns the header of a scaled memory column, suffix is appended to the unit (e.g. "/op")
+func byteHeader(bm *benchmark, value func(r *result) float64, suffix, fallback string) string {
+	if unitMode != unitsGlobal {
+		return fallback
+	}
+
+	unit, _ := suitableByteUnit(maxValue(allResults(bm), value))
+
+	return unit + suffix
+}
+
+// formatBytes renders the memory value of r in a unit chosen for the whole table, r's group
+// or r itself, depending on unitMode
+func formatBytes(bm *benchmark, r *result, value func(r *result) float64, suffix string) string {
+	return renderBytes(bm, r, value, value(r), suffix)
+}
+
+// renderBytes renders v, a memory value of the metric value returns, in the row of r like formatBytes
+func renderBytes(bm *benchmark, r *result, value func(r *result) float64, v float64, suffix string) string {
+	var (
+		unit    string
+		divisor float64
+		samples = sampleValues(bm, r, value)
+	)
+
+	switch unitMode {
+	case unitsGroup:
+		unit, divisor = suitableByteUnit(maxValue((*bm.results)[r.Name], value))
+	case unitsCell:
+		unit, divisor = suitableByteUnit(v)
+	default:
+		_, divisor = suitableByteUnit(maxValue(allResults(bm), value))
+
+		if divisor == 1 {
+			return renderCount(v)
+		}
+		return renderMetric(fmtFloat, v/divisor, samples)
+	}
+
+	if divisor == 1 {
+		return renderCount(v) + " " + unit + suffix
+	}
+	return renderMetric(fmtFloatUnit, v/divisor, samples) + " " + unit + suffix
+}
+
+// countDigits is the minimum number of significant digits of fractional counts, see renderCount
+const countDigits = 3
+
+// renderCount renders counts like B/op and allocs/op, which are whole numbers unless aggregated: integers
+// without decimal places, fractions with countDigits significant digits (but all integer digits)
+func renderCount(v float64) string {
+	f, err := ParseNumberFormat(fmtFloat)
+
+	if err != nil {
+		panic("renderCount(): " + err.Error())
+	}
+
+	digits := countDigits
+
+	if v != 0 {
+		if d := int(math.Floor(math.Log10(math.Abs(v)))) + 1; d > digits {
+			digits = d
+		}
+	}
+
+	return f.Significant(digits).Trimmed().Format(v)
+}
+
+func maxValue(rs []*result, value func(r *result) float64) float64 {
+	var max float64
+
+	for _, r := range rs {
+		if v := value(r); v > max {
+			max = v
+		}
+	}
+
+	return max
+}
+
+func allResults(bm *benchmark) []*result {
+	var all []*result
+
+	for _, rs := range *bm.results {
+		all = append(all, rs...)
+	}
+
+	return all
+}
@@ -0,0 +1,130 @@
+package prettybenchmarks
+
+import "testing"
+
+func Test_formatTime(t *testing.T) {
+	defer func() { unitMode = unitsGlobal }()
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Fast-8      	  100000	     3.2 ns/op\n"),
+		[]byte("Benchmark_Slow_10-8   	  1	     1840000000 ns/op\n"),
+		[]byte("Benchmark_Slow_100-8  	  1	     2000 ns/op\n"),
+	})
+	bm.info.suggestedTiming = "s"
+
+	fast := (*bm.results)["Fast"][0]
+	slow := (*bm.results)["Slow"][0]
+	slowSmall := (*bm.results)["Slow"][1]
+
+	for _, tt := range []struct {
+		mode     string
+		r        *result
+		expected string
+	}{
+		{unitsGlobal, fast, "0.000"},
+		{unitsGlobal, slow, "1.840"},
+		{unitsCell, fast, "3.20 ns"},
+		{unitsCell, slow, "1.84 s"},
+		{unitsCell, slowSmall, "2.00 µs"},
+		{unitsGroup, fast, "3.20 ns"},
+		{unitsGroup, slowSmall, "0.00 s"},
+	} {
+		unitMode = tt.mode
+
+		if actual := formatTime(bm, tt.r, true); actual != tt.expected {
+			t.Errorf("Formatting time %v in %s mode: expected %v, actual %v", tt.r.Speed, tt.mode, tt.expected, actual)
+		}
+	}
+}
+
+func Test_suitableTiming(t *testing.T) {
+	for _, tt := range []struct {
+		input    float64
+		expected string
+	}{
+		{3, "ns"},
+		{1000, "ns"},
+		{1001, "µs"},
+		{2e6, "ms"},
+		{2e9, "s"},
+	} {
+		if actual := suitableTiming(tt.input); actual != tt.expected {
+			t.Errorf("Suggesting time unit for %v: expected %v, actual %v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func Test_formatBytes(t *testing.T) {
+	defer func() {
+		unitMode = unitsGlobal
+		byteMode = bytesRaw
+	}()
+
+	bm := newBenchmark([][]byte{
+		[]byte("Benchmark_Small-8      	  100000	     3 ns/op	     512 B/op	       1 allocs/op\n"),
+		[]byte("Benchmark_Large-8      	  100000	     3 ns/op	 3145728 B/op	       1 allocs/op\n"),
+	})
+
+	small := (*bm.results)["Small"][0]
+	large := (*bm.results)["Large"][0]
+
+	for _, tt := range []struct {
+		unitMode string
+		byteMode string
+		r        *result
+		expected string
+	}{
+		{unitsGlobal, bytesIEC, small, "0.000"},
+		{unitsGlobal, bytesIEC, large, "3.000"},
+		{unitsGlobal, bytesSI, large, "3.146"},
+		{unitsCell, bytesIEC, small, "512 B/op"},
+		{unitsCell, bytesIEC, large, "3.00 MiB/op"},
+		{unitsGroup, bytesSI, large, "3.15 MB/op"},
+	} {
+		unitMode = tt.unitMode
+		byteMode = tt.byteMode
+
+		if actual := formatBytes(bm, tt.r, bytesPerOp, "/op"); actual != tt.expected {
+			t.Errorf("Formatting %v bytes in %s/%s mode: expected %v, actual %v", tt.r.Bps, tt.unitMode, tt.byteMode, tt.expected, actual)
+		}
+	}
+
+	unitMode = unitsGlobal
+	byteMode = bytesIEC
+
+	if actual := byteHeader(bm, bytesPerOp, "/op", "mem/op"); actual != "MiB/op" {
+		t.Errorf("Getting header for scaled bytes: expected %v, actual %v", "MiB/op", actual)
+	}
+}
+
+func Test_renderCount(t *testing.T) {
+	for _, tt := range []struct {
+		input    float64
+		expected string
+	}{
+		{0, "0"},
+		{3, "3"},
+		{1048576, "1,048,576"},
+		{0.5, "0.5"},
+		{2.25, "2.25"},
+		{10.333333, "10.3"},
+		{1234.5678, "1,235"},
+		{0.0012345, "0.00123"},
+	} {
+		if actual := renderCount(tt.input); actual != tt.expected {
+			t.Errorf("Rendering count %v: expected %v, actual %v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func Test_fractionalMemory(t *testing.T) {
+	r, err := newResult([]byte("BenchmarkFoo-8\t100\t3 ns/op\t48.5 B/op\t0.25 allocs/op"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Bps != 48.5 || r.Aps != 0.25 {
+		t.Errorf("Parsing fractional memory values: expected 48.5 B/op and 0.25 allocs/op, actual %v and %v", r.Bps, r.Aps)
+	}
+}